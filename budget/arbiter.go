@@ -0,0 +1,140 @@
+package budget
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Arbiter shares a single pod budget between all scalers running within one autoscaler process.
+// All receivers are safe for concurrent use by scaler goroutines.
+type Arbiter struct {
+	mu sync.Mutex
+
+	budget  int
+	members map[string]*member
+}
+
+type member struct {
+	priority   int
+	guaranteed int
+
+	current int
+	desired int
+	// granted are replicas deployment was allowed to run by the last Acquire, it may be scaling up to them
+	granted int
+}
+
+// reserved returns pods deployment runs or is about to run after granted scale up
+func (m *member) reserved() int {
+	return max(m.current, m.granted)
+}
+
+func New(budget int) *Arbiter {
+	return &Arbiter{
+		budget:  budget,
+		members: map[string]*member{},
+	}
+}
+
+func (a *Arbiter) Budget() int {
+	return a.budget
+}
+
+// Register adds deployment to the budget. Guaranteed pods are reserved for the deployment even when
+// it doesn't use them, so sum of all guaranteed pods cannot exceed the budget.
+func (a *Arbiter) Register(deploymentName string, priority, guaranteed, currentReplicas int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if guaranteed < 0 {
+		return fmt.Errorf("guaranteed number of pods for %v cannot be negative", deploymentName)
+	}
+
+	reserved := guaranteed
+	for name, m := range a.members {
+		if name != deploymentName {
+			reserved += m.guaranteed
+		}
+	}
+
+	if reserved > a.budget {
+		return fmt.Errorf("guaranteed pods (%d) exceed pod budget (%d)", reserved, a.budget)
+	}
+
+	a.members[deploymentName] = &member{
+		priority:   priority,
+		guaranteed: guaranteed,
+		current:    currentReplicas,
+		desired:    currentReplicas,
+		granted:    currentReplicas,
+	}
+
+	return nil
+}
+
+// Acquire records current and desired replicas of deployment and returns the number of replicas
+// it is allowed to run. Only scale ups are limited, already running pods are never taken away.
+//
+// Capacity is reserved for other deployments up to their current, granted or guaranteed pods, whichever is highest,
+// and for outstanding demand of deployments with higher priority. Deployment can always grow up to its guaranteed pods.
+func (a *Arbiter) Acquire(deploymentName string, current, desired int) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	m, ok := a.members[deploymentName]
+	if !ok {
+		zap.S().Warnf("deployment %v is not registered in pod budget, not limiting", deploymentName)
+		return desired
+	}
+
+	m.current = current
+	m.desired = desired
+
+	if desired <= current {
+		m.granted = desired
+		return desired
+	}
+
+	available := a.budget
+
+	for name, other := range a.members {
+		if name == deploymentName {
+			continue
+		}
+
+		reserved := max(other.reserved(), other.guaranteed)
+		available -= reserved
+
+		if other.priority > m.priority && other.desired > reserved {
+			available -= other.desired - reserved
+		}
+	}
+
+	available = max(available, m.guaranteed, current)
+
+	if desired > available {
+		zap.S().With("deployment", deploymentName).Debugf("pod budget limits scale up from %d to %d, desired %d", current, available, desired)
+		m.granted = available
+		return available
+	}
+
+	m.granted = desired
+
+	return desired
+}
+
+// InUse returns number of pods currently accounted in the budget, including pods granted to scale ups.
+func (a *Arbiter) InUse() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var acc int
+
+	for _, m := range a.members {
+		acc += m.reserved()
+	}
+
+	return acc
+}
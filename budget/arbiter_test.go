@@ -0,0 +1,129 @@
+package budget
+
+import (
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Arbiter", func() {
+	var arbiter *Arbiter
+
+	BeforeEach(func() {
+		arbiter = New(10)
+	})
+
+	Describe("Register()", func() {
+		It("Registers deployments within budget", func() {
+			Expect(arbiter.Register("web", 10, 4, 2)).To(Succeed())
+			Expect(arbiter.Register("worker", 0, 6, 1)).To(Succeed())
+			Expect(arbiter.InUse()).To(Equal(3))
+		})
+
+		It("Returns error when guaranteed pods exceed budget", func() {
+			Expect(arbiter.Register("web", 10, 6, 0)).To(Succeed())
+
+			err := arbiter.Register("worker", 0, 5, 0)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("guaranteed pods (11) exceed pod budget (10)"))
+		})
+
+		It("Returns error when guaranteed pods negative", func() {
+			Expect(arbiter.Register("web", 0, -1, 0)).ToNot(Succeed())
+		})
+	})
+
+	Describe("Acquire()", func() {
+		BeforeEach(func() {
+			Expect(arbiter.Register("web", 10, 2, 2)).To(Succeed())
+			Expect(arbiter.Register("worker", 5, 0, 3)).To(Succeed())
+			Expect(arbiter.Register("reports", 0, 1, 0)).To(Succeed())
+		})
+
+		It("Grants scale up within budget", func() {
+			Expect(arbiter.Acquire("worker", 3, 5)).To(Equal(5))
+		})
+
+		It("Limits scale up to the budget", func() {
+			Expect(arbiter.Acquire("worker", 3, 9)).To(Equal(7))
+		})
+
+		It("Never limits scale down", func() {
+			Expect(arbiter.Acquire("worker", 3, 1)).To(Equal(1))
+			Expect(arbiter.InUse()).To(Equal(5))
+		})
+
+		It("Never takes away running pods", func() {
+			Expect(arbiter.Acquire("web", 2, 2)).To(Equal(2))
+			Expect(arbiter.Acquire("worker", 8, 12)).To(Equal(8))
+		})
+
+		It("Always grants guaranteed pods", func() {
+			Expect(arbiter.Acquire("worker", 7, 7)).To(Equal(7))
+			Expect(arbiter.Acquire("reports", 0, 3)).To(Equal(1))
+		})
+
+		It("Reserves capacity for demand of deployments with higher priority", func() {
+			Expect(arbiter.Acquire("web", 2, 6)).To(Equal(6))
+			Expect(arbiter.Acquire("worker", 3, 6)).To(Equal(3))
+		})
+
+		It("Doesn't reserve capacity for demand of deployments with lower priority", func() {
+			Expect(arbiter.Acquire("web", 2, 6)).To(Equal(6))
+			Expect(arbiter.Acquire("worker", 3, 6)).To(Equal(3))
+			// worker still demands 3 more pods, only its running ones are reserved
+			Expect(arbiter.Acquire("web", 2, 7)).To(Equal(6))
+		})
+
+		It("Accounts pods already running in deployments with lower priority", func() {
+			Expect(arbiter.Acquire("worker", 7, 7)).To(Equal(7))
+			Expect(arbiter.Acquire("web", 2, 6)).To(Equal(2))
+		})
+
+		It("Accounts pods granted to scale up which isn't finished yet", func() {
+			Expect(arbiter.Acquire("worker", 3, 6)).To(Equal(6))
+			Expect(arbiter.InUse()).To(Equal(8))
+
+			Expect(arbiter.Acquire("reports", 0, 5)).To(Equal(2))
+			Expect(arbiter.InUse()).To(Equal(10))
+		})
+
+		It("Doesn't grant the same free pods to deployments scaling up at once", func() {
+			arbiter = New(10)
+			Expect(arbiter.Register("a", 0, 0, 5)).To(Succeed())
+			Expect(arbiter.Register("b", 0, 0, 4)).To(Succeed())
+
+			granted := arbiter.Acquire("a", 5, 6) + arbiter.Acquire("b", 4, 5)
+
+			Expect(granted).To(BeNumerically("<=", 10))
+			Expect(arbiter.InUse()).To(Equal(10))
+		})
+
+		It("Releases granted pods not needed anymore", func() {
+			Expect(arbiter.Acquire("worker", 3, 6)).To(Equal(6))
+			Expect(arbiter.Acquire("worker", 3, 3)).To(Equal(3))
+			Expect(arbiter.InUse()).To(Equal(5))
+		})
+
+		It("Does not limit unregistered deployments", func() {
+			Expect(arbiter.Acquire("unknown", 0, 100)).To(Equal(100))
+		})
+
+		It("Is safe for concurrent use", func() {
+			wg := sync.WaitGroup{}
+
+			for i := 0; i < 50; i++ {
+				wg.Add(3)
+				go func() { arbiter.Acquire("web", 2, 4); wg.Done() }()
+				go func() { arbiter.Acquire("worker", 3, 4); wg.Done() }()
+				go func() { arbiter.InUse(); wg.Done() }()
+			}
+
+			wg.Wait()
+
+			Expect(arbiter.InUse()).To(Equal(8))
+		})
+	})
+})
@@ -9,6 +9,11 @@ type Config struct {
 	SlackWebhookUrl string
 	SlackChannel    string
 	ClusterName     string
+
+	PodBudget int
+
+	LeaderElect      bool
+	LeaderElectionID string
 }
 
 func NewWithDefaults() Config {
@@ -0,0 +1,61 @@
+package events
+
+// Gates checked before scaling deployment, reported in DecisionRecord
+const (
+	GateRollout          = "rollout"
+	GateCooldown         = "cooldown"
+	GateMaxLimit         = "max_limit"
+	GateMinLimit         = "min_limit"
+	GateConsecutiveZeros = "consecutive_zeros"
+	GatePodBudget        = "pod_budget"
+	GateCapacity         = "capacity"
+	GateProbeFailures    = "probe_failures"
+)
+
+// Actions reported in DecisionRecord
+const (
+	ActionScaleUp   = "scale_up"
+	ActionScaleDown = "scale_down"
+	ActionRemain    = "remain"
+)
+
+// DecisionRecord explains single autoscaler decision: what it was based on, which gates were checked and what was done
+type DecisionRecord struct {
+	Deployment string         `json:"deployment"`
+	Inputs     DecisionInputs `json:"inputs"`
+	Gates      []DecisionGate `json:"gates"`
+
+	Action          string `json:"action"`
+	FromReplicas    int    `json:"from_replicas"`
+	ToReplicas      int    `json:"to_replicas"`
+	DesiredReplicas int    `json:"desired_replicas"`
+	HeldBy          string `json:"held_by,omitempty"`
+	ScheduledAction string `json:"scheduled_action,omitempty"`
+	Summary         string `json:"summary"`
+}
+
+type DecisionInputs struct {
+	ProbeType    string `json:"probe_type"`
+	ProbeValue   int    `json:"probe_value"`
+	ProbeHistory []int  `json:"probe_history"`
+	Threshold    int    `json:"threshold"`
+
+	ProbeBreakdown map[string]int    `json:"probe_breakdown,omitempty"`
+	ProbeFailures  map[string]string `json:"probe_failures,omitempty"`
+	LatencyBreach  string            `json:"latency_breach,omitempty"`
+
+	CurrentReplicas   int `json:"current_replicas"`
+	AvailableReplicas int `json:"available_replicas"`
+
+	MinPods      int    `json:"min_pods"`
+	MaxPods      int    `json:"max_pods"`
+	LimitsSource string `json:"limits_source"`
+
+	CooldownRemainingSeconds float64 `json:"cooldown_remaining_seconds"`
+}
+
+type DecisionGate struct {
+	Gate   string `json:"gate"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
@@ -4,6 +4,14 @@ import (
 	"fmt"
 )
 
+// Reasons of held scale up, reported as ScalingReason
+const (
+	ReasonPodsUnschedulable     = "pods_unschedulable"
+	ReasonResourceQuotaExceeded = "resource_quota_exceeded"
+	ReasonReplicaFailure        = "replica_failure"
+	ReasonPodBudgetExhausted    = "pod_budget_exhausted"
+)
+
 type ScalingEventData struct {
 	CurrentReplicas  int     `json:"current_replicas"`  
 	TargetReplicas   int     `json:"target_replicas"`   
@@ -16,6 +24,9 @@ type ScalingEventData struct {
 	ScalingDirection string `json:"scaling_direction"`
 	ProbeType        string `json:"probe_type"`       
 	ScalingReason    string `json:"scaling_reason"`   
+	Held             bool   `json:"held"`
+
+	Explanation *DecisionRecord `json:"explanation,omitempty"`
 
 	DeploymentName string `json:"deployment_name"`
 	Namespace      string `json:"namespace"`
@@ -26,6 +37,20 @@ type ScalingEventData struct {
 }
 
 func (e *ScalingEventData) BuildHumanMessage() string {
+	if e.Held {
+		return fmt.Sprintf(
+			"Held scale %s at %d replicas, wanted %d | %s: %d/%d (%.1f%%) | Reason: %s",
+			e.ScalingDirection,
+			e.CurrentReplicas,
+			e.TargetReplicas,
+			e.ProbeType,
+			e.ProbeValue,
+			e.Threshold,
+			e.LoadPercentage,
+			e.ScalingReason,
+		)
+	}
+
 	return fmt.Sprintf(
 		"Scaled %s from %d to %d replicas | %s: %d/%d (%.1f%%) | Reason: %s",
 		e.ScalingDirection,
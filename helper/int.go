@@ -1,6 +1,9 @@
 package helper
 
 import (
+	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 )
 
@@ -35,3 +38,18 @@ func IntSliceToString(input []int) string {
 
 	return b
 }
+
+// IntMapToString builds string of map entries sorted by key, eg. `a: 1, b: 2`
+func IntMapToString(input map[string]int) string {
+	b := ""
+
+	for _, k := range slices.Sorted(maps.Keys(input)) {
+		if len(b) > 0 {
+			b += ", "
+		}
+
+		b += fmt.Sprintf("%s: %d", k, input[k])
+	}
+
+	return b
+}
@@ -41,4 +41,12 @@ var _ = Describe("Int", func() {
 			Entry("Multiple elements", []int{1, 5, 9}, "1, 5, 9"),
 		)
 	})
+
+	Describe("IntMapToString()", func() {
+		DescribeTable("Properly builds string from map of ints",
+			func(input map[string]int, expectation string) { Expect(IntMapToString(input)).To(Equal(expectation)) },
+			Entry("Empty map", map[string]int{}, ""),
+			Entry("Multiple elements sorted by key", map[string]int{"pdfs-dlq": 0, "emails-dlq": 3}, "emails-dlq: 3, pdfs-dlq: 0"),
+		)
+	})
 })
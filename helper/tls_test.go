@@ -0,0 +1,61 @@
+package helper
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TLS", func() {
+	Describe("NewTLSConfig()", func() {
+		writeFile := func(content []byte) string {
+			path := filepath.Join(GinkgoT().TempDir(), "ca.pem")
+			Expect(os.WriteFile(path, content, 0o600)).To(Succeed())
+
+			return path
+		}
+
+		It("Uses system CAs without CA file", func() {
+			config, err := NewTLSConfig("", true)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.RootCAs).To(BeNil())
+			Expect(config.InsecureSkipVerify).To(BeTrue())
+			Expect(config.MinVersion).To(Equal(uint16(tls.VersionTLS12)))
+		})
+
+		It("Verifies server with CA certificate read from file", func() {
+			server := httptest.NewTLSServer(http.NotFoundHandler())
+			DeferCleanup(server.Close)
+
+			config, err := NewTLSConfig(writeFile(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})), false)
+			Expect(err).ToNot(HaveOccurred())
+
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+			resp, err := client.Get(server.URL)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Body.Close()).To(Succeed())
+		})
+
+		It("Returns error when CA file is missing", func() {
+			_, err := NewTLSConfig("/nonexistent/ca.pem", false)
+
+			Expect(err).To(MatchError(ContainSubstring("failed to read CA file")))
+		})
+
+		It("Returns error when CA file has no certificates", func() {
+			path := writeFile([]byte("not a certificate"))
+
+			_, err := NewTLSConfig(path, false)
+
+			Expect(err).To(MatchError("no certificates found in CA file " + path))
+		})
+	})
+})
@@ -0,0 +1,126 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+
+	"github.com/AirHelp/autoscaler/events"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CheckScaleUpCapacity verifies whether deployment can grow by additionalReplicas pods.
+// Returns reason of the blocker (see events.Reason* constants) or empty string when there is enough headroom.
+func (s *Service) CheckScaleUpCapacity(ctx context.Context, deployment *appsv1.Deployment, additionalReplicas int) (string, error) {
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentReplicaFailure && condition.Status == corev1.ConditionTrue {
+			if strings.Contains(condition.Message, "exceeded quota") {
+				return events.ReasonResourceQuotaExceeded, nil
+			}
+
+			return events.ReasonReplicaFailure, nil
+		}
+	}
+
+	pods, err := s.GetPodsFromDeployment(ctx, deployment, map[string]string{})
+	if err != nil {
+		return "", err
+	}
+
+	for _, pod := range pods.Items {
+		if isPodUnschedulable(pod) {
+			zap.S().With("deployment", deployment.Name, "pod", pod.Name).Debug("found unschedulable pod")
+			return events.ReasonPodsUnschedulable, nil
+		}
+	}
+
+	quotas, err := s.Client.CoreV1().ResourceQuotas(s.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	required := podResources(deployment.Spec.Template.Spec, additionalReplicas)
+
+	for _, quota := range quotas.Items {
+		// Scoped quotas apply only to some pods, we can't tell whether deployment's pods are affected
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+
+		for name, requested := range required {
+			hard, ok := quota.Status.Hard[name]
+			if !ok {
+				continue
+			}
+
+			used := quota.Status.Used[name]
+			used.Add(requested)
+
+			if used.Cmp(hard) > 0 {
+				zap.S().With("deployment", deployment.Name).Debugf("resource quota %v has no headroom for %v", quota.Name, name)
+				return events.ReasonResourceQuotaExceeded, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func isPodUnschedulable(pod corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodPending {
+		return false
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse && condition.Reason == corev1.PodReasonUnschedulable {
+			return true
+		}
+	}
+
+	return false
+}
+
+// podResources sums quota tracked resources of given number of pods built from pod spec
+func podResources(spec corev1.PodSpec, count int) corev1.ResourceList {
+	pods := *resource.NewQuantity(int64(count), resource.DecimalSI)
+
+	result := corev1.ResourceList{
+		corev1.ResourcePods:                     pods,
+		corev1.ResourceName("count/pods"):       pods,
+		corev1.ResourceRequestsCPU:              resource.Quantity{},
+		corev1.ResourceRequestsMemory:           resource.Quantity{},
+		corev1.ResourceCPU:                      resource.Quantity{},
+		corev1.ResourceMemory:                   resource.Quantity{},
+		corev1.ResourceLimitsCPU:                resource.Quantity{},
+		corev1.ResourceLimitsMemory:             resource.Quantity{},
+		corev1.ResourceRequestsEphemeralStorage: resource.Quantity{},
+	}
+
+	for i := 0; i < count; i++ {
+		for _, container := range spec.Containers {
+			addQuantity(result, corev1.ResourceRequestsCPU, container.Resources.Requests, corev1.ResourceCPU)
+			addQuantity(result, corev1.ResourceCPU, container.Resources.Requests, corev1.ResourceCPU)
+			addQuantity(result, corev1.ResourceRequestsMemory, container.Resources.Requests, corev1.ResourceMemory)
+			addQuantity(result, corev1.ResourceMemory, container.Resources.Requests, corev1.ResourceMemory)
+			addQuantity(result, corev1.ResourceRequestsEphemeralStorage, container.Resources.Requests, corev1.ResourceEphemeralStorage)
+			addQuantity(result, corev1.ResourceLimitsCPU, container.Resources.Limits, corev1.ResourceCPU)
+			addQuantity(result, corev1.ResourceLimitsMemory, container.Resources.Limits, corev1.ResourceMemory)
+		}
+	}
+
+	return result
+}
+
+func addQuantity(result corev1.ResourceList, name corev1.ResourceName, source corev1.ResourceList, sourceName corev1.ResourceName) {
+	q, ok := source[sourceName]
+	if !ok {
+		return
+	}
+
+	current := result[name]
+	current.Add(q)
+	result[name] = current
+}
@@ -0,0 +1,164 @@
+package k8s
+
+import (
+	"context"
+
+	"github.com/AirHelp/autoscaler/events"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Capacity", func() {
+	var (
+		namespace  string
+		ctx        context.Context
+		deployment *appsv1.Deployment
+		pod        *corev1.Pod
+		quota      *corev1.ResourceQuota
+	)
+
+	BeforeEach(func() {
+		namespace = "ugabuga"
+		ctx = context.TODO()
+
+		r := int32(2)
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "some-deployment",
+				Namespace: namespace,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &r,
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "some-app"},
+				},
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name: "app",
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU:    resource.MustParse("500m"),
+										corev1.ResourceMemory: resource.MustParse("256Mi"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "some-deployment-1231-sxada",
+				Namespace: namespace,
+				Labels:    map[string]string{"app": "some-app"},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+			},
+		}
+
+		quota = &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "compute",
+				Namespace: namespace,
+			},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{
+					corev1.ResourcePods:        resource.MustParse("10"),
+					corev1.ResourceRequestsCPU: resource.MustParse("2"),
+				},
+				Used: corev1.ResourceList{
+					corev1.ResourcePods:        resource.MustParse("2"),
+					corev1.ResourceRequestsCPU: resource.MustParse("1"),
+				},
+			},
+		}
+	})
+
+	Describe("CheckScaleUpCapacity()", func() {
+		It("Returns no blocker when there is enough headroom", func() {
+			svc := Service{Client: fake.NewSimpleClientset(deployment, pod, quota), Namespace: namespace}
+
+			res, err := svc.CheckScaleUpCapacity(ctx, deployment, 2)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(BeEmpty())
+		})
+
+		It("Returns quota blocker when requested resources exceed quota", func() {
+			svc := Service{Client: fake.NewSimpleClientset(deployment, pod, quota), Namespace: namespace}
+
+			res, err := svc.CheckScaleUpCapacity(ctx, deployment, 3)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(events.ReasonResourceQuotaExceeded))
+		})
+
+		It("Returns quota blocker when pods count exceeds quota", func() {
+			quota.Status.Used[corev1.ResourcePods] = resource.MustParse("10")
+			svc := Service{Client: fake.NewSimpleClientset(deployment, pod, quota), Namespace: namespace}
+
+			res, err := svc.CheckScaleUpCapacity(ctx, deployment, 1)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(events.ReasonResourceQuotaExceeded))
+		})
+
+		It("Ignores scoped quotas", func() {
+			quota.Spec.Scopes = []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort}
+			svc := Service{Client: fake.NewSimpleClientset(deployment, pod, quota), Namespace: namespace}
+
+			res, err := svc.CheckScaleUpCapacity(ctx, deployment, 3)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(BeEmpty())
+		})
+
+		It("Returns unschedulable blocker when deployment has unschedulable pods", func() {
+			pod.Status.Phase = corev1.PodPending
+			pod.Status.Conditions = []corev1.PodCondition{
+				{
+					Type:   corev1.PodScheduled,
+					Status: corev1.ConditionFalse,
+					Reason: corev1.PodReasonUnschedulable,
+				},
+			}
+			svc := Service{Client: fake.NewSimpleClientset(deployment, pod, quota), Namespace: namespace}
+
+			res, err := svc.CheckScaleUpCapacity(ctx, deployment, 1)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(events.ReasonPodsUnschedulable))
+		})
+
+		DescribeTable("Returns blocker from deployment replica failure condition",
+			func(message, expectation string) {
+				deployment.Status.Conditions = []appsv1.DeploymentCondition{
+					{
+						Type:    appsv1.DeploymentReplicaFailure,
+						Status:  corev1.ConditionTrue,
+						Reason:  "FailedCreate",
+						Message: message,
+					},
+				}
+				svc := Service{Client: fake.NewSimpleClientset(deployment, pod), Namespace: namespace}
+
+				res, err := svc.CheckScaleUpCapacity(ctx, deployment, 1)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(expectation))
+			},
+			Entry("When quota exceeded", `pods "some-deployment-1231-qwert" is forbidden: exceeded quota: compute`, events.ReasonResourceQuotaExceeded),
+			Entry("When other failure", `pods "some-deployment-1231-qwert" is forbidden: error looking up service account`, events.ReasonReplicaFailure),
+		)
+	})
+})
@@ -0,0 +1,79 @@
+package k8s
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+// RunLeaderElection competes for the lease until ctx is done. onStartedLeading is called with context
+// cancelled when leadership is lost, after losing leadership instance goes back to standby and competes again.
+// Next term starts and RunLeaderElection returns only after onStartedLeading of previous term returned.
+func (s *Service) RunLeaderElection(ctx context.Context, leaseName, identity string, onStartedLeading func(context.Context)) {
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      leaseName,
+			Namespace: s.Namespace,
+		},
+		Client: s.Client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}
+
+	for ctx.Err() == nil {
+		var (
+			mu      sync.Mutex
+			leading sync.WaitGroup
+		)
+
+		leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			ReleaseOnCancel: true,
+			LeaseDuration:   leaseDuration,
+			RenewDeadline:   renewDeadline,
+			RetryPeriod:     retryPeriod,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(leaderCtx context.Context) {
+					// callback runs in its own goroutine, it may start after the term already ended
+					mu.Lock()
+					if leaderCtx.Err() != nil {
+						mu.Unlock()
+						return
+					}
+					leading.Add(1)
+					mu.Unlock()
+
+					defer leading.Done()
+
+					zap.S().Infof("%v became leader", identity)
+					onStartedLeading(leaderCtx)
+				},
+				OnStoppedLeading: func() {
+					zap.S().Infof("%v stopped leading", identity)
+				},
+				OnNewLeader: func(currentLeader string) {
+					if currentLeader != identity {
+						zap.S().Infof("%v is the leader, running as standby", currentLeader)
+					}
+				},
+			},
+		})
+
+		// term context is cancelled once RunOrDie returns, so callback can't start leading after this point
+		mu.Lock()
+		leading.Wait()
+		mu.Unlock()
+	}
+}
@@ -0,0 +1,65 @@
+package k8s
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Leader election", func() {
+	It("Runs callback once leadership is acquired and releases lease on shutdown", func() {
+		namespace := "ugabuga"
+		client := fake.NewSimpleClientset()
+		svc := Service{Client: client, Namespace: namespace}
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		leading := make(chan struct{})
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+			svc.RunLeaderElection(ctx, "autoscaler", "autoscaler-1", func(leaderCtx context.Context) {
+				close(leading)
+				<-leaderCtx.Done()
+			})
+		}()
+
+		Eventually(leading).Should(BeClosed())
+
+		lease, err := client.CoordinationV1().Leases(namespace).Get(context.TODO(), "autoscaler", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*lease.Spec.HolderIdentity).To(Equal("autoscaler-1"))
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("Returns only after callback of the last term returned", func() {
+		svc := Service{Client: fake.NewSimpleClientset(), Namespace: "ugabuga"}
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		leading := make(chan struct{})
+		release := make(chan struct{})
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+			svc.RunLeaderElection(ctx, "autoscaler", "autoscaler-1", func(leaderCtx context.Context) {
+				close(leading)
+				<-leaderCtx.Done()
+				<-release
+			})
+		}()
+
+		Eventually(leading).Should(BeClosed())
+
+		cancel()
+		Consistently(done, "200ms").ShouldNot(BeClosed())
+
+		close(release)
+		Eventually(done).Should(BeClosed())
+	})
+})
@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -14,6 +15,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -63,6 +65,34 @@ func (s *Service) ScaleDeployment(ctx context.Context, deployment *appsv1.Deploy
 	return s.Client.AppsV1().Deployments(s.Namespace).Update(ctx, deployment, metav1.UpdateOptions{})
 }
 
+func (s *Service) AnnotateDeployment(ctx context.Context, name string, annotations map[string]string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Client.AppsV1().Deployments(s.Namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+func (s *Service) AnnotatePod(ctx context.Context, name string, annotations map[string]string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Client.CoreV1().Pods(s.Namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
 func (s *Service) GetPodsFromDeployment(ctx context.Context, deployment *appsv1.Deployment, additionalLabels map[string]string) (*corev1.PodList, error) {
 	selectorLabels := labels.Set(deployment.Spec.Selector.MatchLabels)
 
@@ -79,11 +109,14 @@ func (s *Service) CreateScalingEvent(ctx context.Context, deployment *appsv1.Dep
 	now := metav1.NewTime(time.Now())
 
 	eventType := "Normal"
-	if eventData.ScalingReason == "at_max_limit" || eventData.ScalingReason == "at_min_limit" {
+	if eventData.ScalingReason == "at_max_limit" || eventData.ScalingReason == "at_min_limit" || eventData.Held {
 		eventType = "Warning"
 	}
 
 	reason := "Scaled" + capitalizeFirst(eventData.ScalingDirection)
+	if eventData.Held {
+		reason = "Scale" + capitalizeFirst(eventData.ScalingDirection) + "Held"
+	}
 
 	event := &corev1.Event{
 		ObjectMeta: metav1.ObjectMeta{
@@ -133,6 +166,14 @@ func (s *Service) CreateScalingEvent(ctx context.Context, deployment *appsv1.Dep
 		Type:           eventType,
 	}
 
+	if eventData.Explanation != nil {
+		explanation, err := json.Marshal(eventData.Explanation)
+		if err != nil {
+			return err
+		}
+		event.Annotations["decision-explanation"] = string(explanation)
+	}
+
 	_, err := s.Client.CoreV1().Events(s.Namespace).Create(ctx, event, metav1.CreateOptions{})
 	return err
 }
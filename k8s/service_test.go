@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/AirHelp/autoscaler/events"
 	. "github.com/onsi/ginkgo/v2"
@@ -327,6 +328,62 @@ var _ = Describe("Service with fake client", func() {
 		})
 	})
 
+	Describe("AnnotateDeployment()", func() {
+		It("adds annotations keeping existing ones", func() {
+			r := int32(2)
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "some-deployment",
+					Namespace:   namespace,
+					Annotations: map[string]string{"existing": "1"},
+				},
+				Spec: appsv1.DeploymentSpec{Replicas: &r},
+			}
+
+			client = fake.NewSimpleClientset(deployment)
+
+			svc := Service{
+				Client:    client,
+				Namespace: namespace,
+			}
+
+			err := svc.AnnotateDeployment(ctx, "some-deployment", map[string]string{"autoscaler.airhelp.com/state": "{}"})
+
+			Expect(err).ToNot(HaveOccurred())
+
+			deploymentFromApi, _ := client.AppsV1().Deployments(namespace).Get(ctx, "some-deployment", metav1.GetOptions{})
+			Expect(deploymentFromApi.Annotations).To(Equal(map[string]string{
+				"existing":                     "1",
+				"autoscaler.airhelp.com/state": "{}",
+			}))
+		})
+	})
+
+	Describe("AnnotatePod()", func() {
+		It("adds annotations to pod", func() {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "some-deployment-1231-sxada",
+					Namespace: namespace,
+				},
+			}
+
+			client = fake.NewSimpleClientset(pod)
+
+			svc := Service{
+				Client:    client,
+				Namespace: namespace,
+			}
+
+			err := svc.AnnotatePod(ctx, "some-deployment-1231-sxada", map[string]string{"controller.kubernetes.io/pod-deletion-cost": "10"})
+
+			Expect(err).ToNot(HaveOccurred())
+
+			podFromApi, _ := client.CoreV1().Pods(namespace).Get(ctx, "some-deployment-1231-sxada", metav1.GetOptions{})
+			Expect(podFromApi.Annotations).To(Equal(map[string]string{"controller.kubernetes.io/pod-deletion-cost": "10"}))
+		})
+	})
+
 	Describe("CreateScalingEvent()", func() {
 		var deployment *appsv1.Deployment
 
@@ -437,6 +494,76 @@ var _ = Describe("Service with fake client", func() {
 			Expect(event.Type).To(Equal("Normal"))
 		})
 
+		It("creates warning event when scale up was held", func() {
+			svc := Service{
+				Client:    client,
+				Namespace: namespace,
+			}
+
+			eventData := &events.ScalingEventData{
+				CurrentReplicas:  2,
+				TargetReplicas:   3,
+				ProbeValue:       250,
+				Threshold:        100,
+				LoadPercentage:   250.0,
+				ScalingDirection: "up",
+				ProbeType:        "sqs",
+				ScalingReason:    events.ReasonResourceQuotaExceeded,
+				Held:             true,
+				DeploymentName:   "test-deployment",
+				Namespace:        namespace,
+				Environment:      "test",
+			}
+			eventData.HumanMessage = eventData.BuildHumanMessage()
+
+			err := svc.CreateScalingEvent(ctx, deployment, eventData)
+
+			Expect(err).ToNot(HaveOccurred())
+
+			kubeEvents, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(kubeEvents.Items).To(HaveLen(1))
+
+			event := kubeEvents.Items[0]
+			Expect(event.Reason).To(Equal("ScaleUpHeld"))
+			Expect(event.Message).To(Equal("Held scale up at 2 replicas, wanted 3 | sqs: 250/100 (250.0%) | Reason: resource_quota_exceeded"))
+			Expect(event.Type).To(Equal("Warning"))
+			Expect(event.Labels["scaling-reason"]).To(Equal("resource_quota_exceeded"))
+		})
+
+		It("attaches decision explanation to the event", func() {
+			svc := Service{
+				Client:    client,
+				Namespace: namespace,
+			}
+
+			eventData := &events.ScalingEventData{
+				CurrentReplicas:  2,
+				TargetReplicas:   3,
+				ScalingDirection: "up",
+				ProbeType:        "sqs",
+				ScalingReason:    "high_load",
+				DeploymentName:   "test-deployment",
+				Explanation: &events.DecisionRecord{
+					Deployment: "test-deployment",
+					Gates:      []events.DecisionGate{{Gate: events.GateCooldown, Passed: true}},
+					Action:     events.ActionScaleUp,
+				},
+			}
+
+			err := svc.CreateScalingEvent(ctx, deployment, eventData)
+
+			Expect(err).ToNot(HaveOccurred())
+
+			kubeEvents, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(kubeEvents.Items).To(HaveLen(1))
+
+			var explanation events.DecisionRecord
+			Expect(json.Unmarshal([]byte(kubeEvents.Items[0].Annotations["decision-explanation"]), &explanation)).To(Succeed())
+			Expect(explanation).To(Equal(*eventData.Explanation))
+		})
+
 		It("creates events with unique names based on timestamp", func() {
 			svc := Service{
 				Client:    client,
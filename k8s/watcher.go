@@ -0,0 +1,80 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DeploymentWatcher keeps informer cache of deployments in namespace warm and remembers
+// when replicas of each deployment were changed for the last time, no matter who changed them.
+type DeploymentWatcher struct {
+	mu                   sync.RWMutex
+	lastReplicasChangeAt map[string]time.Time
+}
+
+var now = time.Now
+
+func (s *Service) WatchDeployments(ctx context.Context) (*DeploymentWatcher, error) {
+	w := &DeploymentWatcher{
+		lastReplicasChangeAt: map[string]time.Time{},
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(s.Client, 0, informers.WithNamespace(s.Namespace))
+	informer := factory.Apps().V1().Deployments().Informer()
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldDeployment, ok := oldObj.(*appsv1.Deployment)
+			if !ok {
+				return
+			}
+
+			newDeployment, ok := newObj.(*appsv1.Deployment)
+			if !ok {
+				return
+			}
+
+			w.observe(oldDeployment, newDeployment)
+		},
+	})
+	if err != nil {
+		return w, err
+	}
+
+	factory.Start(ctx.Done())
+
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return w, errors.New("failed to sync deployments cache")
+	}
+
+	return w, nil
+}
+
+func (w *DeploymentWatcher) observe(oldDeployment, newDeployment *appsv1.Deployment) {
+	if oldDeployment.Spec.Replicas == nil || newDeployment.Spec.Replicas == nil || *oldDeployment.Spec.Replicas == *newDeployment.Spec.Replicas {
+		return
+	}
+
+	zap.S().With("deployment", newDeployment.Name).Debugf("observed replicas change from %d to %d", *oldDeployment.Spec.Replicas, *newDeployment.Spec.Replicas)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.lastReplicasChangeAt[newDeployment.Name] = now()
+}
+
+// LastReplicasChangeAt returns when replicas of deployment were changed for the last time, zero time when
+// no change was observed
+func (w *DeploymentWatcher) LastReplicasChangeAt(deploymentName string) time.Time {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.lastReplicasChangeAt[deploymentName]
+}
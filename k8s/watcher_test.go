@@ -0,0 +1,73 @@
+package k8s
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("DeploymentWatcher", func() {
+	var (
+		namespace  string
+		ctx        context.Context
+		cancel     context.CancelFunc
+		deployment *appsv1.Deployment
+		client     *fake.Clientset
+		changedAt  time.Time
+	)
+
+	BeforeEach(func() {
+		namespace = "ugabuga"
+		ctx, cancel = context.WithCancel(context.TODO())
+
+		r := int32(2)
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "some-deployment",
+				Namespace: namespace,
+			},
+			Spec: appsv1.DeploymentSpec{Replicas: &r},
+		}
+
+		client = fake.NewSimpleClientset(deployment)
+
+		changedAt = time.Date(2020, 12, 14, 15, 0, 0, 0, time.UTC)
+		now = func() time.Time { return changedAt }
+	})
+
+	AfterEach(func() {
+		cancel()
+		now = time.Now
+	})
+
+	It("Remembers when replicas of deployment were changed", func() {
+		svc := Service{Client: client, Namespace: namespace}
+
+		watcher, err := svc.WatchDeployments(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(watcher.LastReplicasChangeAt("some-deployment")).To(BeZero())
+
+		_, err = svc.ScaleDeployment(ctx, deployment, 3)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(func() time.Time { return watcher.LastReplicasChangeAt("some-deployment") }).Should(Equal(changedAt))
+	})
+
+	It("Ignores changes not affecting replicas", func() {
+		svc := Service{Client: client, Namespace: namespace}
+
+		watcher, err := svc.WatchDeployments(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		deployment.Labels = map[string]string{"team": "test"}
+		_, err = client.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Consistently(func() time.Time { return watcher.LastReplicasChangeAt("some-deployment") }, 200*time.Millisecond).Should(BeZero())
+	})
+})
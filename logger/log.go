@@ -7,8 +7,11 @@ import (
 
 func InitLogger(namespace, environment, logLevel string) {
 	var zapLogLevel zapcore.Level = zap.InfoLevel
-	if logLevel == "debug" {
+	switch logLevel {
+	case "debug":
 		zapLogLevel = zap.DebugLevel
+	case "warn":
+		zapLogLevel = zap.WarnLevel
 	}
 
 	zapConfig := zap.NewProductionConfig()
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"syscall"
 
+	"github.com/AirHelp/autoscaler/budget"
 	"github.com/AirHelp/autoscaler/config"
 	"github.com/AirHelp/autoscaler/k8s"
 	"github.com/AirHelp/autoscaler/logger"
@@ -32,6 +33,11 @@ type ScalerEntity interface {
 }
 
 func init() {
+	if isSimulateCommand() {
+		log.InitLogger("", "", "warn")
+		return
+	}
+
 	cfg = parseStartingFlags()
 	logLevel := "info"
 	if cfg.Verbose {
@@ -41,6 +47,14 @@ func init() {
 }
 
 func main() {
+	if isSimulateCommand() {
+		if err := runSimulate(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if cfg.Version {
 		fmt.Println(versionString())
 		os.Exit(0)
@@ -79,15 +93,50 @@ func main() {
 		zap.S().Debug("slack client initialized successfully")
 	}
 
+	var podBudget *budget.Arbiter
+
+	if cfg.PodBudget > 0 {
+		zap.S().Infof("sharing pod budget of %d pods between all deployments", cfg.PodBudget)
+		podBudget = budget.New(cfg.PodBudget)
+	}
+
+	var tracker scaler.ReplicasChangeTracker
+
+	if cfg.LeaderElect {
+		zap.S().Debug("starting deployments watcher")
+		watcher, err := k8sSvc.WatchDeployments(ctx)
+		if err != nil {
+			zap.S().With("error", err).Error("failed to watch deployments")
+			panic(err)
+		}
+		tracker = watcher
+	}
+
 	zap.S().Debug("initializing scalers on the all enabled deployments")
 
-	waitGroup := sync.WaitGroup{}
+	scalerConfigs := map[string]scaler.Config{}
+	for deployment, rawYamlConfig := range configMap.Data {
+		if scalerConfig, err := scaler.ParseRawScalerConfig(rawYamlConfig); err == nil {
+			scalerConfigs[deployment] = scalerConfig
+		}
+	}
+
+	linksErrors := scaler.ValidateLinks(scalerConfigs)
+
+	var scalers []ScalerEntity
+
+	sqsServices := sqs.NewServicePool()
 
 	for deployment, rawYamlConfig := range configMap.Data {
 		var sqsService *sqs.SQSService
 		var scalerInstance ScalerEntity
 
-		sqsService, err = InitializeSQSService(ctx, rawYamlConfig)
+		if err, ok := linksErrors[deployment]; ok {
+			zap.S().With("error", err).Errorf("invalid linked deployment config for %v, skipping", deployment)
+			continue
+		}
+
+		sqsService, err = InitializeSQSService(ctx, sqsServices, rawYamlConfig)
 		if err != nil {
 			zap.S().With("error", err).Errorf("failed to initialize autoscaler for %v: , skipping", deployment)
 			continue
@@ -100,6 +149,8 @@ func main() {
 			Notifiers:      notifiers,
 			K8sService:     k8sSvc,
 			SQSService:     sqsService,
+			PodBudget:      podBudget,
+			Tracker:        tracker,
 			GlobalConfig:   cfg,
 		})
 
@@ -108,24 +159,57 @@ func main() {
 			continue
 		}
 
-		go func() {
-			scalerInstance.Start(ctx)
-			waitGroup.Done()
-		}()
-
-		waitGroup.Add(1)
+		scalers = append(scalers, scalerInstance)
 	}
 
-	zap.S().Debug("initializing scalers on the all enabled deployments")
+	zap.S().Debug("initialized scalers on the all enabled deployments")
+
+	// closed once scalers stopped, leader election returns only after scalers of the last term finished
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		if !cfg.LeaderElect {
+			runScalers(ctx, scalers)
+			return
+		}
+
+		identity, err := os.Hostname()
+		if err != nil {
+			zap.S().With("error", err).Error("failed to determine leader election identity")
+			panic(err)
+		}
+
+		zap.S().Infof("starting leader election as %v, standing by", identity)
+		k8sSvc.RunLeaderElection(ctx, cfg.LeaderElectionID, identity, func(leaderCtx context.Context) {
+			runScalers(leaderCtx, scalers)
+		})
+	}()
 
 	<-interruptChan
 	cancel()
 
-	waitGroup.Wait()
+	<-done
 
 	zap.S().Info("received shutdown, shutting down")
 }
 
+func runScalers(ctx context.Context, scalers []ScalerEntity) {
+	waitGroup := sync.WaitGroup{}
+
+	for _, scalerInstance := range scalers {
+		waitGroup.Add(1)
+
+		go func() {
+			scalerInstance.Start(ctx)
+			waitGroup.Done()
+		}()
+	}
+
+	waitGroup.Wait()
+}
+
 func parseStartingFlags() config.Config {
 	cfg := config.NewWithDefaults()
 	flag.BoolVarP(&cfg.Verbose, "verbose", "v", false, "Debug mode")
@@ -136,12 +220,15 @@ func parseStartingFlags() config.Config {
 	flag.StringVar(&cfg.SlackWebhookUrl, "slack_url", "", "Slack Webhook URL to use")
 	flag.StringVar(&cfg.SlackChannel, "slack_channel", "", "Slack channel to send messages to")
 	flag.StringVar(&cfg.ClusterName, "cluster_name", "", "Name of cluster")
+	flag.BoolVar(&cfg.LeaderElect, "leader_elect", false, "Run with leader election, allows running multiple replicas of autoscaler")
+	flag.StringVar(&cfg.LeaderElectionID, "leader_election_id", "autoscaler", "Name of Lease used for leader election")
+	flag.IntVar(&cfg.PodBudget, "pod_budget", 0, "Maximum number of pods shared by all managed deployments, 0 disables budget")
 	flag.Parse()
 
 	return cfg
 }
 
-func InitializeSQSService(ctx context.Context, rawConfig string) (*sqs.SQSService, error) {
+func InitializeSQSService(ctx context.Context, sqsServices *sqs.ServicePool, rawConfig string) (*sqs.SQSService, error) {
 	var sqsService *sqs.SQSService
 	config, err := scaler.ParseRawScalerConfig(rawConfig)
 	if err != nil {
@@ -153,7 +240,7 @@ func InitializeSQSService(ctx context.Context, rawConfig string) (*sqs.SQSServic
 		return sqsService, nil
 	}
 
-	sqsService, err = sqs.NewSQSService(ctx)
+	sqsService, err = sqsServices.Service(ctx, config.Sqs)
 	if err != nil {
 		zap.S().Debugf("failed to initialize SQS client: %v", err)
 		return sqsService, err
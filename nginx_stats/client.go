@@ -2,34 +2,113 @@ package nginx_stats
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
-	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 )
 
+const (
+	SchemeHTTP  = "http"
+	SchemeHTTPS = "https"
+
+	// idleConnections kept per pod, stats are read from each pod few times per check
+	idleConnections    = 2
+	idleConnectionTime = 90 * time.Second
+)
+
+// ClientConfig tells where stats are served and how to read number from them
+type ClientConfig struct {
+	Endpoint string
+	// Format is one of FormatPlain, FormatStubStatus, FormatJSON or FormatPrometheus, plain is used when empty
+	Format string
+	// Field is path of number in JSON stats or name of Prometheus metric
+	Field string
+	// Metric is read from stub_status page, active connections are read when empty
+	Metric string
+	// Scheme is http or https, http is used when empty
+	Scheme string
+	// Headers are sent with each request, eg. authorization
+	Headers map[string]string
+	// TLS configures verification of server for https scheme, system CAs are used when nil
+	TLS *tls.Config
+}
+
 type NginxClient struct {
-	endpoint string
+	endpoint   string
+	scheme     string
+	headers    map[string]string
+	parse      parser
+	httpClient *http.Client
 }
 
+// NewClient creates client reading bare number of active connections from endpoint
 func NewClient(endpoint string) (*NginxClient, error) {
+	return NewClientWithConfig(ClientConfig{Endpoint: endpoint})
+}
+
+func NewClientWithConfig(config ClientConfig) (*NginxClient, error) {
+	metric := config.Metric
+	if metric == "" {
+		metric = MetricActive
+	}
+
+	parse, err := newParser(config.Format, config.Field, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := config.Scheme
+	if scheme == "" {
+		scheme = SchemeHTTP
+	}
+
+	if scheme != SchemeHTTP && scheme != SchemeHTTPS {
+		return nil, fmt.Errorf("unknown scheme `%v`, expected %v or %v", scheme, SchemeHTTP, SchemeHTTPS)
+	}
+
 	return &NginxClient{
-		endpoint: endpoint,
+		endpoint:   strings.TrimPrefix(config.Endpoint, "/"),
+		scheme:     scheme,
+		headers:    config.Headers,
+		parse:      parse,
+		httpClient: newHTTPClient(config.TLS),
 	}, nil
 }
 
-func (c *NginxClient) GetActiveConnections(ctx context.Context, ip string) (int, error) {
-	url := fmt.Sprintf("http://%v/%v", ip, c.endpoint)
+// newHTTPClient creates client keeping connections to pods alive between consecutive reads and checks
+func newHTTPClient(tlsConfig *tls.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = idleConnections
+	transport.IdleConnTimeout = idleConnectionTime
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Transport: transport}
+}
+
+// GetMetric returns number read from stats of nginx served at given address, eg. `10.0.0.1` or `10.0.0.1:8080`
+func (c *NginxClient) GetMetric(ctx context.Context, address string) (int, error) {
+	url := fmt.Sprintf("%v://%v/%v", c.scheme, address, c.endpoint)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create request")
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	for name, value := range c.headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get %v: %v", url, err)
 	}
@@ -49,11 +128,5 @@ func (c *NginxClient) GetActiveConnections(ctx context.Context, ip string) (int,
 		return 0, fmt.Errorf("failed to read the response body: %v", err)
 	}
 
-	result, err := strconv.Atoi(strings.TrimSpace(string(body)))
-
-	if err != nil {
-		return 0, fmt.Errorf("returned active connections is not number: %v", string(body))
-	}
-
-	return result, nil
+	return c.parse(body)
 }
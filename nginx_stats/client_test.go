@@ -14,9 +14,9 @@ import (
 )
 
 var _ = Describe("Client", func() {
-	Describe("GetActiveConnections()", func() {
+	Describe("GetMetric()", func() {
 		var (
-			client   NginxClient
+			client   *NginxClient
 			ip       string
 			endpoint string
 
@@ -26,12 +26,14 @@ var _ = Describe("Client", func() {
 		BeforeEach(func() {
 			ctx = context.Background()
 
-			endpoint = "stats/active_connections"
+			endpoint = "/stats/active_connections"
 			ip = "0.0.0.0"
 
-			client = NginxClient{
-				endpoint: endpoint,
-			}
+			var err error
+			client, err = NewClient(endpoint)
+			Expect(err).ToNot(HaveOccurred())
+
+			gock.InterceptClient(client.httpClient)
 		})
 
 		AfterEach(func() {
@@ -46,6 +48,7 @@ var _ = Describe("Client", func() {
 				// Gock cannot simulate such a scenario
 				// So we need to create a fake server
 				gock.Off()
+				gock.RestoreClient(client.httpClient)
 
 				srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 					time.Sleep(50 * time.Millisecond)
@@ -64,7 +67,7 @@ var _ = Describe("Client", func() {
 
 				srvWithoutProtocol := strings.TrimPrefix(srv.URL, "http://")
 
-				res, err := client.GetActiveConnections(ctx, srvWithoutProtocol)
+				res, err := client.GetMetric(ctx, srvWithoutProtocol)
 
 				Expect(res).To(Equal(0))
 				Expect(err).To(HaveOccurred())
@@ -79,11 +82,78 @@ var _ = Describe("Client", func() {
 					Reply(200).
 					BodyString("13")
 
-				res, err := client.GetActiveConnections(ctx, ip)
+				res, err := client.GetMetric(ctx, ip)
 
 				Expect(res).To(Equal(13))
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			It("Returns metric read from stub_status page", func() {
+				client, err := NewClientWithConfig(ClientConfig{Endpoint: endpoint, Format: FormatStubStatus, Metric: MetricWriting})
+				Expect(err).ToNot(HaveOccurred())
+				gock.InterceptClient(client.httpClient)
+
+				gock.New("http://" + ip).
+					Get("/stats/active_connections").
+					Reply(200).
+					BodyString(stubStatus)
+
+				res, err := client.GetMetric(ctx, ip)
+
+				Expect(res).To(Equal(179))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("When served over https with auth", func() {
+			var srv *httptest.Server
+
+			BeforeEach(func() {
+				gock.Off()
+
+				srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path != "/stats/active_connections" || r.Header.Get("Authorization") != "Bearer secret" {
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+
+					w.Write([]byte("7"))
+				}))
+			})
+
+			AfterEach(func() {
+				srv.Close()
+			})
+
+			It("Sends headers and verifies server with given TLS config", func() {
+				client, err := NewClientWithConfig(ClientConfig{
+					Endpoint: endpoint,
+					Scheme:   SchemeHTTPS,
+					Headers:  map[string]string{"Authorization": "Bearer secret"},
+					TLS:      srv.Client().Transport.(*http.Transport).TLSClientConfig,
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				res, err := client.GetMetric(ctx, strings.TrimPrefix(srv.URL, "https://"))
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(7))
+			})
+
+			It("Rejects server which can't be verified", func() {
+				client, err := NewClientWithConfig(ClientConfig{Endpoint: endpoint, Scheme: SchemeHTTPS})
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = client.GetMetric(ctx, strings.TrimPrefix(srv.URL, "https://"))
+
+				Expect(err).To(MatchError(ContainSubstring("certificate")))
+			})
+		})
+
+		It("Rejects unknown scheme", func() {
+			_, err := NewClientWithConfig(ClientConfig{Endpoint: endpoint, Scheme: "ftp"})
+
+			Expect(err).To(MatchError("unknown scheme `ftp`, expected http or https"))
 		})
 
 		Context("When not ok", func() {
@@ -94,7 +164,7 @@ var _ = Describe("Client", func() {
 						Get("/stats/active_connections").
 						ReplyError(respErr)
 
-					res, err := client.GetActiveConnections(ctx, ip)
+					res, err := client.GetMetric(ctx, ip)
 
 					Expect(res).To(Equal(0))
 					Expect(err).To(Equal(errors.New("failed to get http://0.0.0.0/stats/active_connections: Get \"http://0.0.0.0/stats/active_connections\": unreachable")))
@@ -109,7 +179,7 @@ var _ = Describe("Client", func() {
 						Reply(500).
 						BodyString("internal server error")
 
-					res, err := client.GetActiveConnections(ctx, ip)
+					res, err := client.GetMetric(ctx, ip)
 
 					Expect(res).To(Equal(0))
 					Expect(err).To(Equal(errors.New("expected 200 response, got 500")))
@@ -123,13 +193,31 @@ var _ = Describe("Client", func() {
 						Reply(200).
 						BodyString("asdf")
 
-					res, err := client.GetActiveConnections(ctx, ip)
+					res, err := client.GetMetric(ctx, ip)
 
 					Expect(res).To(Equal(0))
 					Expect(err).To(Equal(errors.New("returned active connections is not number: asdf")))
 				})
 			})
 
+			Context("When response doesn't match format", func() {
+				It("returns 0 and error", func() {
+					client, err := NewClientWithConfig(ClientConfig{Endpoint: endpoint, Format: FormatStubStatus})
+					Expect(err).ToNot(HaveOccurred())
+					gock.InterceptClient(client.httpClient)
+
+					gock.New("http://" + ip).
+						Get("/stats/active_connections").
+						Reply(200).
+						BodyString("13")
+
+					res, err := client.GetMetric(ctx, ip)
+
+					Expect(res).To(Equal(0))
+					Expect(err).To(MatchError(ContainSubstring("unexpected stub_status response")))
+				})
+			})
+
 		})
 	})
 })
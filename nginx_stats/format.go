@@ -0,0 +1,222 @@
+package nginx_stats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Formats of stats response
+const (
+	// FormatPlain is bare number, eg. returned with `return 200 $connections_active`
+	FormatPlain = "plain"
+	// FormatStubStatus is page of ngx_http_stub_status_module
+	FormatStubStatus = "stub_status"
+	// FormatJSON is JSON document, number is found by field path
+	FormatJSON = "json"
+	// FormatPrometheus is Prometheus text exposition, number is sum of samples of metric
+	FormatPrometheus = "prometheus"
+)
+
+// Metrics read from stats
+const (
+	// MetricActive is number of open client connections including idle keep-alive ones
+	MetricActive = "active"
+	// MetricWriting is number of connections nginx is writing response to, ie. requests being processed
+	MetricWriting = "writing"
+	// MetricRequests is counter of client requests handled since nginx started
+	MetricRequests = "requests"
+)
+
+// parser reads number from stats response
+type parser func(body []byte) (int, error)
+
+// StubStatus is page of ngx_http_stub_status_module:
+//
+//	Active connections: 291
+//	server accepts handled requests
+//	 16630948 16630948 31070465
+//	Reading: 6 Writing: 179 Waiting: 106
+type StubStatus struct {
+	Active   int
+	Accepts  int
+	Handled  int
+	Requests int
+	Reading  int
+	Writing  int
+	Waiting  int
+}
+
+func newParser(format, field, metric string) (parser, error) {
+	switch metric {
+	case MetricActive, MetricWriting, MetricRequests:
+	default:
+		return nil, fmt.Errorf("unknown metric `%v`, expected one of %v, %v, %v", metric, MetricActive, MetricWriting, MetricRequests)
+	}
+
+	if (format == FormatJSON || format == FormatPrometheus) && field == "" {
+		return nil, fmt.Errorf("field is required for %v format", format)
+	}
+
+	switch format {
+	case "", FormatPlain:
+		return func(body []byte) (int, error) { return parsePlain(body, metric) }, nil
+	case FormatStubStatus:
+		return func(body []byte) (int, error) {
+			status, err := ParseStubStatus(body)
+			if err != nil {
+				return 0, err
+			}
+
+			return status.Metric(metric), nil
+		}, nil
+	case FormatJSON:
+		return func(body []byte) (int, error) { return parseJSON(body, field) }, nil
+	case FormatPrometheus:
+		return func(body []byte) (int, error) { return parsePrometheus(body, field) }, nil
+	default:
+		return nil, fmt.Errorf("unknown format `%v`, expected one of %v, %v, %v, %v", format, FormatPlain, FormatStubStatus, FormatJSON, FormatPrometheus)
+	}
+}
+
+var metricDescriptions = map[string]string{
+	MetricActive:   "active connections",
+	MetricWriting:  "writing connections",
+	MetricRequests: "requests",
+}
+
+func parsePlain(body []byte, metric string) (int, error) {
+	result, err := strconv.Atoi(strings.TrimSpace(string(body)))
+	if err != nil {
+		return 0, fmt.Errorf("returned %v is not number: %v", metricDescriptions[metric], string(body))
+	}
+
+	return result, nil
+}
+
+// ParseStubStatus reads page of ngx_http_stub_status_module
+func ParseStubStatus(body []byte) (StubStatus, error) {
+	var status StubStatus
+
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if len(lines) != 4 {
+		return status, fmt.Errorf("unexpected stub_status response: %v", string(body))
+	}
+
+	if _, err := fmt.Sscanf(strings.TrimSpace(lines[0]), "Active connections: %d", &status.Active); err != nil {
+		return status, fmt.Errorf("unexpected stub_status active connections `%v`: %w", lines[0], err)
+	}
+
+	if _, err := fmt.Sscanf(strings.TrimSpace(lines[2]), "%d %d %d", &status.Accepts, &status.Handled, &status.Requests); err != nil {
+		return status, fmt.Errorf("unexpected stub_status counters `%v`: %w", lines[2], err)
+	}
+
+	if _, err := fmt.Sscanf(strings.TrimSpace(lines[3]), "Reading: %d Writing: %d Waiting: %d", &status.Reading, &status.Writing, &status.Waiting); err != nil {
+		return status, fmt.Errorf("unexpected stub_status connections `%v`: %w", lines[3], err)
+	}
+
+	return status, nil
+}
+
+// Metric returns value of given metric
+func (s StubStatus) Metric(metric string) int {
+	switch metric {
+	case MetricWriting:
+		return s.Writing
+	case MetricRequests:
+		return s.Requests
+	default:
+		return s.Active
+	}
+}
+
+// parseJSON reads number found by dot separated path, eg. `connections.active`
+func parseJSON(body []byte, path string) (int, error) {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return 0, fmt.Errorf("failed to parse JSON stats: %w", err)
+	}
+
+	for _, field := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return 0, fmt.Errorf("field `%v` not found in JSON stats", path)
+		}
+
+		if value, ok = object[field]; !ok {
+			return 0, fmt.Errorf("field `%v` not found in JSON stats", path)
+		}
+	}
+
+	number, ok := value.(float64)
+	if !ok {
+		return 0, fmt.Errorf("field `%v` of JSON stats is not number: %v", path, value)
+	}
+
+	return int(math.Round(number)), nil
+}
+
+// parsePrometheus sums samples of given metric in Prometheus text exposition, eg. of each server zone
+func parsePrometheus(body []byte, name string) (int, error) {
+	var (
+		sum   float64
+		found bool
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		metric, rest, ok := cutMetricName(line)
+		if !ok || metric != name {
+			continue
+		}
+
+		// value may be followed by timestamp
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return 0, fmt.Errorf("sample of metric `%v` has no value: %v", name, line)
+		}
+
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return 0, fmt.Errorf("sample of metric `%v` is not number: %v", name, line)
+		}
+
+		sum += value
+		found = true
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read Prometheus stats: %w", err)
+	}
+
+	if !found {
+		return 0, fmt.Errorf("metric `%v` not found in Prometheus stats", name)
+	}
+
+	return int(math.Round(sum)), nil
+}
+
+// cutMetricName splits sample line into metric name and rest of line following labels
+func cutMetricName(line string) (name, rest string, ok bool) {
+	if i := strings.IndexByte(line, '{'); i >= 0 {
+		end := strings.LastIndexByte(line, '}')
+		if end < i {
+			return "", "", false
+		}
+
+		return line[:i], line[end+1:], true
+	}
+
+	name, rest, ok = strings.Cut(line, " ")
+
+	return name, rest, ok
+}
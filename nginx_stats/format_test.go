@@ -0,0 +1,65 @@
+package nginx_stats
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const stubStatus = `Active connections: 291 
+server accepts handled requests
+ 16630948 16630948 31070465 
+Reading: 6 Writing: 179 Waiting: 106 
+`
+
+var _ = Describe("Formats", func() {
+	DescribeTable("Reads metric",
+		func(format, field, metric, body string, expected int) {
+			parse, err := newParser(format, field, metric)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(parse([]byte(body))).To(Equal(expected))
+		},
+		Entry("Plain", FormatPlain, "", MetricActive, "13\n", 13),
+		Entry("Plain by default", "", "", MetricActive, "13", 13),
+		Entry("Active connections of stub_status", FormatStubStatus, "", MetricActive, stubStatus, 291),
+		Entry("Writing connections of stub_status", FormatStubStatus, "", MetricWriting, stubStatus, 179),
+		Entry("Requests of stub_status", FormatStubStatus, "", MetricRequests, stubStatus, 31070465),
+		Entry("JSON field", FormatJSON, "connections.active", MetricActive, `{"connections": {"active": 12, "idle": 3}}`, 12),
+		Entry("Prometheus metric summed over labels", FormatPrometheus, "nginx_connections_writing", MetricWriting,
+			"# HELP nginx_connections_writing Writing connections\n"+
+				"# TYPE nginx_connections_writing gauge\n"+
+				"nginx_connections_writing{zone=\"a\"} 4\n"+
+				"nginx_connections_writing{zone=\"b\"} 5 1700000000000\n"+
+				"nginx_connections_reading 7\n", 9),
+		Entry("Prometheus metric without labels", FormatPrometheus, "nginx_connections_active", MetricActive, "nginx_connections_active 3e1\n", 30),
+	)
+
+	DescribeTable("Returns error for unexpected response",
+		func(format, field, body, expected string) {
+			parse, err := newParser(format, field, MetricActive)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = parse([]byte(body))
+
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		},
+		Entry("Plain", FormatPlain, "", "asdf", "returned active connections is not number: asdf"),
+		Entry("Incomplete stub_status", FormatStubStatus, "", "Active connections: 1", "unexpected stub_status response"),
+		Entry("Malformed stub_status", FormatStubStatus, "", "Active connections: x\n\n1 2 3\nReading: 1 Writing: 2 Waiting: 3", "unexpected stub_status active connections"),
+		Entry("Missing JSON field", FormatJSON, "connections.writing", `{"connections": {"active": 12}}`, "field `connections.writing` not found"),
+		Entry("JSON field which isn't number", FormatJSON, "connections", `{"connections": {"active": 12}}`, "is not number"),
+		Entry("Missing Prometheus metric", FormatPrometheus, "nginx_connections_writing", "nginx_connections_active 3\n", "metric `nginx_connections_writing` not found"),
+	)
+
+	DescribeTable("Rejects invalid config",
+		func(format, field, metric, expected string) {
+			_, err := newParser(format, field, metric)
+
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		},
+		Entry("Unknown format", "xml", "", MetricActive, "unknown format `xml`"),
+		Entry("Unknown metric", FormatStubStatus, "", "idle", "unknown metric `idle`"),
+		Entry("JSON without field", FormatJSON, "", MetricActive, "field is required for json format"),
+		Entry("Prometheus without field", FormatPrometheus, "", MetricActive, "field is required for prometheus format"),
+	)
+})
@@ -35,6 +35,20 @@ func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
 	return m.recorder
 }
 
+// Alert mocks base method.
+func (m *MockNotifier) Alert(arg0 context.Context, arg1 notification.AlertPayload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Alert", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Alert indicates an expected call of Alert.
+func (mr *MockNotifierMockRecorder) Alert(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Alert", reflect.TypeOf((*MockNotifier)(nil).Alert), arg0, arg1)
+}
+
 // Kind mocks base method.
 func (m *MockNotifier) Kind() string {
 	m.ctrl.T.Helper()
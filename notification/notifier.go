@@ -3,11 +3,14 @@ package notification
 import (
 	"context"
 	"time"
+
+	"github.com/AirHelp/autoscaler/events"
 )
 
 //go:generate mockgen -destination=mock/notification_mock.go -package notificationMock github.com/AirHelp/autoscaler/notification Notifier
 type Notifier interface {
 	Notify(context.Context, NotificationPayload) error
+	Alert(context.Context, AlertPayload) error
 	Kind() string
 }
 
@@ -19,4 +22,20 @@ type NotificationPayload struct {
 	ChangedAt        time.Time
 	Source           string
 	LastProbeResults []int
+	Explanation      *events.DecisionRecord
+	DeadLetterDepths map[string]int
+}
+
+// AlertPayload describes problem which needs attention although autoscaler didn't change deployment,
+// eg. messages landing in dead-letter queues
+type AlertPayload struct {
+	Alert          string
+	Environment    string
+	DeploymentName string
+	Namespace      string
+	RaisedAt       time.Time
+	Source         string
+
+	DeadLetterDepths         map[string]int
+	PreviousDeadLetterDepths map[string]int
 }
@@ -75,6 +75,66 @@ func (c Client) Notify(ctx context.Context, payload notification.NotificationPay
 		},
 	}
 
+	if len(payload.DeadLetterDepths) > 0 {
+		att.Fields = append(att.Fields, slack.AttachmentField{
+			Title: "Dead-letter queues",
+			Value: helper.IntMapToString(payload.DeadLetterDepths),
+		})
+	}
+
+	return c.post(ctx, att)
+}
+
+func (c Client) Alert(ctx context.Context, payload notification.AlertPayload) error {
+	att := slack.Attachment{
+		Color:      "danger",
+		AuthorIcon: c.icon,
+		Pretext:    "Autoscaler noticed a problem in deployment",
+		Footer:     fmt.Sprintf("autoscaler @ %v", payload.RaisedAt.Format(time.RFC3339)),
+		Fields: []slack.AttachmentField{
+			{
+				Title: "Alert",
+				Value: payload.Alert,
+			},
+			{
+				Title: "Dead-letter queues",
+				Value: helper.IntMapToString(payload.DeadLetterDepths),
+			},
+			{
+				Title: "Previous dead-letter queues",
+				Value: helper.IntMapToString(payload.PreviousDeadLetterDepths),
+			},
+			{
+				Title: "Cluster name",
+				Value: c.clusterName,
+			},
+			{
+				Title: "Deployment",
+				Value: payload.DeploymentName,
+				Short: true,
+			},
+			{
+				Title: "Namespace",
+				Value: payload.Namespace,
+				Short: true,
+			},
+			{
+				Title: "Environment",
+				Value: payload.Environment,
+				Short: true,
+			},
+			{
+				Title: "Source",
+				Value: payload.Source,
+				Short: true,
+			},
+		},
+	}
+
+	return c.post(ctx, att)
+}
+
+func (c Client) post(ctx context.Context, att slack.Attachment) error {
 	msg := slack.WebhookMessage{
 		Username:    c.username,
 		IconEmoji:   c.icon,
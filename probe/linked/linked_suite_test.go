@@ -0,0 +1,13 @@
+package linked_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestLinked(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Linked Probe Suite")
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/AirHelp/autoscaler/probe/linked (interfaces: K8SClient)
+
+// Package linkedMock is a generated GoMock package.
+package linkedMock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	v1 "k8s.io/api/apps/v1"
+)
+
+// MockK8SClient is a mock of K8SClient interface.
+type MockK8SClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockK8SClientMockRecorder
+}
+
+// MockK8SClientMockRecorder is the mock recorder for MockK8SClient.
+type MockK8SClientMockRecorder struct {
+	mock *MockK8SClient
+}
+
+// NewMockK8SClient creates a new mock instance.
+func NewMockK8SClient(ctrl *gomock.Controller) *MockK8SClient {
+	mock := &MockK8SClient{ctrl: ctrl}
+	mock.recorder = &MockK8SClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockK8SClient) EXPECT() *MockK8SClientMockRecorder {
+	return m.recorder
+}
+
+// GetDeployment mocks base method.
+func (m *MockK8SClient) GetDeployment(arg0 context.Context, arg1 string) (*v1.Deployment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeployment", arg0, arg1)
+	ret0, _ := ret[0].(*v1.Deployment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeployment indicates an expected call of GetDeployment.
+func (mr *MockK8SClientMockRecorder) GetDeployment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeployment", reflect.TypeOf((*MockK8SClient)(nil).GetDeployment), arg0, arg1)
+}
@@ -0,0 +1,103 @@
+package linked
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+const (
+	roundingCeil  = "ceil"
+	roundingFloor = "floor"
+	roundingRound = "round"
+)
+
+// Config derives desired replicas of deployment from replicas of another managed deployment:
+// desired = rounding(source replicas * ratio) + offset
+type Config struct {
+	Deployment string  `yaml:"deployment"`
+	Ratio      float64 `yaml:"ratio"`
+	Rounding   string  `yaml:"rounding"`
+	Offset     int     `yaml:"offset"`
+}
+
+type Probe struct {
+	k8sService K8SClient
+	config     Config
+}
+
+//go:generate mockgen -destination=mock/k8s_client_mock.go -package linkedMock github.com/AirHelp/autoscaler/probe/linked K8SClient
+type K8SClient interface {
+	GetDeployment(context.Context, string) (*appsv1.Deployment, error)
+}
+
+var (
+	ErrNoDeploymentSpecified = errors.New("no source deployment provided")
+	ErrInvalidRatio          = errors.New("ratio has to be greater than 0")
+)
+
+func New(config *Config, k8sSvc K8SClient) (*Probe, error) {
+	if config.Deployment == "" {
+		return &Probe{}, ErrNoDeploymentSpecified
+	}
+
+	if config.Ratio <= 0 {
+		return &Probe{}, ErrInvalidRatio
+	}
+
+	c := *config
+
+	switch c.Rounding {
+	case "":
+		c.Rounding = roundingCeil
+	case roundingCeil, roundingFloor, roundingRound:
+	default:
+		return &Probe{}, fmt.Errorf("unknown rounding mode: %v", c.Rounding)
+	}
+
+	return &Probe{
+		k8sService: k8sSvc,
+		config:     c,
+	}, nil
+}
+
+func (p *Probe) Kind() string {
+	return "linked"
+}
+
+// Check returns number of replicas of source deployment
+func (p *Probe) Check(ctx context.Context) (int, error) {
+	deployment, err := p.k8sService.GetDeployment(ctx, p.config.Deployment)
+	if err != nil {
+		return 0, err
+	}
+
+	if deployment.Spec.Replicas == nil {
+		return 0, nil
+	}
+
+	zap.S().Debugf("source deployment %v is at %d replicas", p.config.Deployment, *deployment.Spec.Replicas)
+
+	return int(*deployment.Spec.Replicas), nil
+}
+
+// DesiredReplicas calculates desired replicas for given number of source deployment replicas
+func (p *Probe) DesiredReplicas(sourceReplicas int) int {
+	// get rid of float precision errors, eg. 10 * 0.3 = 3.0000000000000004 which would be rounded up to 4
+	value := math.Round(float64(sourceReplicas)*p.config.Ratio*1e6) / 1e6
+
+	switch p.config.Rounding {
+	case roundingFloor:
+		value = math.Floor(value)
+	case roundingRound:
+		value = math.Round(value)
+	default:
+		value = math.Ceil(value)
+	}
+
+	return int(value) + p.config.Offset
+}
@@ -0,0 +1,117 @@
+package linked
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	linkedMock "github.com/AirHelp/autoscaler/probe/linked/mock"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+var _ = Describe("Probe", func() {
+	var (
+		mockCtrl       *gomock.Controller
+		k8sServiceMock *linkedMock.MockK8SClient
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		k8sServiceMock = linkedMock.NewMockK8SClient(mockCtrl)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Describe("New()", func() {
+		It("Returns error when no source deployment provided", func() {
+			probe, err := New(&Config{Ratio: 0.25}, k8sServiceMock)
+
+			Expect(probe).To(Equal(&Probe{}))
+			Expect(err).To(Equal(ErrNoDeploymentSpecified))
+		})
+
+		It("Returns error when ratio is not positive", func() {
+			probe, err := New(&Config{Deployment: "claims-worker"}, k8sServiceMock)
+
+			Expect(probe).To(Equal(&Probe{}))
+			Expect(err).To(Equal(ErrInvalidRatio))
+		})
+
+		It("Returns error when rounding mode is unknown", func() {
+			probe, err := New(&Config{Deployment: "claims-worker", Ratio: 0.25, Rounding: "truncate"}, k8sServiceMock)
+
+			Expect(probe).To(Equal(&Probe{}))
+			Expect(err).To(Equal(fmt.Errorf("unknown rounding mode: truncate")))
+		})
+
+		It("Defaults rounding mode to ceil", func() {
+			probe, err := New(&Config{Deployment: "claims-worker", Ratio: 0.25}, k8sServiceMock)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(probe.config.Rounding).To(Equal("ceil"))
+		})
+	})
+
+	Describe("Probe receiver", func() {
+		var (
+			ctx   context.Context
+			probe *Probe
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			var err error
+			probe, err = New(&Config{Deployment: "claims-worker", Ratio: 0.25}, k8sServiceMock)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Describe("Kind()", func() {
+			It("Return linked string", func() {
+				Expect(probe.Kind()).To(Equal("linked"))
+			})
+		})
+
+		Describe("Check()", func() {
+			It("Returns replicas of source deployment", func() {
+				r := int32(9)
+				k8sServiceMock.EXPECT().GetDeployment(ctx, "claims-worker").Return(&appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &r}}, nil)
+
+				res, err := probe.Check(ctx)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(9))
+			})
+
+			It("Returns error when fetching source deployment fails", func() {
+				k8sServiceMock.EXPECT().GetDeployment(ctx, "claims-worker").Return(&appsv1.Deployment{}, errors.New("not found"))
+
+				res, err := probe.Check(ctx)
+
+				Expect(err).To(HaveOccurred())
+				Expect(res).To(Equal(0))
+			})
+		})
+
+		Describe("DesiredReplicas()", func() {
+			DescribeTable("Properly derives desired replicas from source replicas",
+				func(config Config, sourceReplicas, expectation int) {
+					probe.config = config
+					Expect(probe.DesiredReplicas(sourceReplicas)).To(Equal(expectation))
+				},
+				Entry("Ceil", Config{Ratio: 0.25, Rounding: "ceil"}, 9, 3),
+				Entry("Floor", Config{Ratio: 0.25, Rounding: "floor"}, 9, 2),
+				Entry("Round down", Config{Ratio: 0.25, Rounding: "round"}, 9, 2),
+				Entry("Round up", Config{Ratio: 0.25, Rounding: "round"}, 10, 3),
+				Entry("With offset", Config{Ratio: 0.25, Rounding: "ceil", Offset: 1}, 8, 3),
+				Entry("When source at zero", Config{Ratio: 0.25, Rounding: "ceil"}, 0, 0),
+				Entry("Without float precision errors", Config{Ratio: 0.3, Rounding: "ceil"}, 10, 3),
+			)
+		})
+	})
+})
@@ -34,17 +34,17 @@ func (m *MockNginxClient) EXPECT() *MockNginxClientMockRecorder {
 	return m.recorder
 }
 
-// GetActiveConnections mocks base method.
-func (m *MockNginxClient) GetActiveConnections(arg0 context.Context, arg1 string) (int, error) {
+// GetMetric mocks base method.
+func (m *MockNginxClient) GetMetric(arg0 context.Context, arg1 string) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetActiveConnections", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetMetric", arg0, arg1)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetActiveConnections indicates an expected call of GetActiveConnections.
-func (mr *MockNginxClientMockRecorder) GetActiveConnections(arg0, arg1 interface{}) *gomock.Call {
+// GetMetric indicates an expected call of GetMetric.
+func (mr *MockNginxClientMockRecorder) GetMetric(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveConnections", reflect.TypeOf((*MockNginxClient)(nil).GetActiveConnections), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetric", reflect.TypeOf((*MockNginxClient)(nil).GetMetric), arg0, arg1)
 }
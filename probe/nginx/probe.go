@@ -2,10 +2,16 @@ package nginx
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
+	"maps"
 	"math"
+	"net"
+	"strconv"
 	"time"
 
+	"github.com/AirHelp/autoscaler/helper"
 	"github.com/AirHelp/autoscaler/nginx_stats"
 	"github.com/AirHelp/autoscaler/stat"
 	"go.uber.org/zap"
@@ -23,11 +29,34 @@ const (
 )
 
 type Config struct {
-	Endpoint         string        `yaml:"endpoint"`
+	Endpoint string `yaml:"endpoint"`
+	// Format of stats served by endpoint: plain, stub_status, json or prometheus
+	Format string `yaml:"format"`
+	// Field is path of number in JSON stats or name of Prometheus metric
+	Field string `yaml:"field"`
+	// Metric read from stub_status page: active, writing or requests. Requests counter is turned into requests per second.
+	Metric           string        `yaml:"metric"`
 	Statistic        string        `yaml:"statistic"`
 	ConsecutiveReads int           `yaml:"consecutive_reads"`
 	Timeout          time.Duration `yaml:"timeout"`
 	RequestTimeout   time.Duration `yaml:"request_timeout"`
+	// PodSelector are labels of pods serving traffic, `type: web` is used when not set
+	PodSelector map[string]string `yaml:"pod_selector"`
+	// Port is number or name of container port serving stats, default port of scheme is used when empty
+	Port   string `yaml:"port"`
+	Scheme string `yaml:"scheme"`
+	// Headers are sent with each request for stats, eg. authorization
+	Headers map[string]string `yaml:"headers"`
+	TLS     TLSConfig         `yaml:"tls"`
+}
+
+// TLSConfig configures verification of pods serving stats over https, it's also used by pod deletion cost endpoint
+type TLSConfig struct {
+	// CAFile is PEM encoded CA certificate used to verify pods instead of system ones
+	CAFile string `yaml:"ca_file"`
+	// ServerName is expected in certificate of pods, as they are requested by IP
+	ServerName         string `yaml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
 }
 
 type Probe struct {
@@ -36,10 +65,17 @@ type Probe struct {
 
 	deployment *appsv1.Deployment
 
+	podSelector map[string]string
+	port        string
+
+	metric           string
 	statistic        string
 	consecutiveReads int
 	timeout          time.Duration
 	requestTimeout   time.Duration
+
+	// podRates are requests per second of each pod measured by the last check
+	podRates map[string]int
 }
 
 //go:generate mockgen -destination=mock/k8s_client_mock.go -package nginxMock github.com/AirHelp/autoscaler/probe/nginx K8SClient
@@ -49,7 +85,7 @@ type K8SClient interface {
 
 //go:generate mockgen -destination=mock/nginx_client_mock.go -package nginxMock github.com/AirHelp/autoscaler/probe/nginx NginxClient
 type NginxClient interface {
-	GetActiveConnections(context.Context, string) (int, error)
+	GetMetric(context.Context, string) (int, error)
 }
 
 func New(config *Config, k8sSvc K8SClient, deployment *appsv1.Deployment) (*Probe, error) {
@@ -83,7 +119,37 @@ func New(config *Config, k8sSvc K8SClient, deployment *appsv1.Deployment) (*Prob
 		requestTimeout = defaultRequestTimeout
 	}
 
-	nginxClient, err := nginx_stats.NewClient(endpoint)
+	metric := config.Metric
+
+	if metric == "" {
+		metric = nginx_stats.MetricActive
+	}
+
+	if metric == nginx_stats.MetricRequests && consecutiveReads < 2 {
+		return nil, fmt.Errorf("at least 2 consecutive reads are required to compute rate of %v", metric)
+	}
+
+	podSelector := config.PodSelector
+
+	if podSelector == nil {
+		podSelector = defaultPodSelector
+	}
+
+	tlsConfig, err := config.TLS.Build()
+
+	if err != nil {
+		return nil, err
+	}
+
+	nginxClient, err := nginx_stats.NewClientWithConfig(nginx_stats.ClientConfig{
+		Endpoint: endpoint,
+		Format:   config.Format,
+		Field:    config.Field,
+		Metric:   metric,
+		Scheme:   config.Scheme,
+		Headers:  config.Headers,
+		TLS:      tlsConfig,
+	})
 
 	if err != nil {
 		return nil, err
@@ -95,6 +161,10 @@ func New(config *Config, k8sSvc K8SClient, deployment *appsv1.Deployment) (*Prob
 
 		deployment: deployment,
 
+		podSelector: podSelector,
+		port:        config.Port,
+
+		metric:           metric,
 		statistic:        statistic,
 		consecutiveReads: consecutiveReads,
 		timeout:          timeout,
@@ -106,14 +176,17 @@ func (p *Probe) Kind() string {
 	return "nginx"
 }
 
-var additionalExpectedWebPodLabels = map[string]string{
+// now is a variable to make reading time stubbable in tests
+var now = time.Now
+
+var defaultPodSelector = map[string]string{
 	"type": "web",
 }
 
 func (p *Probe) Check(ctx context.Context) (int, error) {
 	var acc int
 
-	pods, err := p.k8sService.GetPodsFromDeployment(ctx, p.deployment, additionalExpectedWebPodLabels)
+	pods, err := p.k8sService.GetPodsFromDeployment(ctx, p.deployment, p.podSelector)
 
 	if err != nil {
 		zap.S().With("error", err).Warn("failed to get pods for deployment")
@@ -144,11 +217,15 @@ func (p *Probe) Check(ctx context.Context) (int, error) {
 
 	zap.S().Debug("all pods ready and serving traffic")
 
-	var fullResults [][]int
+	var (
+		fullResults []map[string]int
+		readAt      []time.Time
+	)
 
 	for i := 0; i < p.consecutiveReads; i++ {
-		fullResults = append(fullResults, []int{})
+		readAt = append(readAt, now())
 
+		// groups each consecutive run in one collection with results from all pods
 		result, err := p.fetchActiveConnectionsFromPods(pods)
 
 		if err != nil {
@@ -156,23 +233,27 @@ func (p *Probe) Check(ctx context.Context) (int, error) {
 			return 0, err
 		}
 
-		// groups each consecutive run in one collection with results from all pods
-		for _, activeConnections := range result {
-			fullResults[i] = append(fullResults[i], activeConnections)
-		}
+		fullResults = append(fullResults, result)
 
 		time.Sleep(p.timeout)
 	}
 
-	connections := []int{}
-	for _, results := range fullResults {
-		sum := 0
+	var connections []int
 
-		for _, result := range results {
-			sum += result
-		}
+	if p.metric == nginx_stats.MetricRequests {
+		connections, p.podRates = requestRates(fullResults, readAt)
+
+		zap.S().Debugf("requests per second of pods: %+v", p.podRates)
+	} else {
+		for _, results := range fullResults {
+			sum := 0
 
-		connections = append(connections, sum)
+			for _, result := range results {
+				sum += result
+			}
+
+			connections = append(connections, sum)
+		}
 	}
 
 	zap.S().Debugf("connections slice gathered by probe: %+v", connections)
@@ -189,6 +270,88 @@ func (p *Probe) Check(ctx context.Context) (int, error) {
 	return acc, nil
 }
 
+// requestRates turns requests counters of pods read at given times into requests per second of all pods between each
+// consecutive reads, and requests per second of each pod over all reads
+func requestRates(counters []map[string]int, readAt []time.Time) ([]int, map[string]int) {
+	rates := []int{}
+	podRequests := map[string]int{}
+
+	for i := 1; i < len(counters); i++ {
+		requests := 0
+
+		for pod, counter := range counters[i] {
+			previous, ok := counters[i-1][pod]
+			if !ok {
+				continue
+			}
+
+			delta := counterDelta(previous, counter)
+			requests += delta
+			podRequests[pod] += delta
+		}
+
+		rates = append(rates, perSecond(requests, readAt[i].Sub(readAt[i-1])))
+	}
+
+	podRates := map[string]int{}
+	if len(readAt) > 1 {
+		for pod, requests := range podRequests {
+			podRates[pod] = perSecond(requests, readAt[len(readAt)-1].Sub(readAt[0]))
+		}
+	}
+
+	return rates, podRates
+}
+
+// counterDelta returns requests handled between two reads of counter. Counter lower than before means nginx was
+// restarted in between and counts from zero again.
+func counterDelta(previous, current int) int {
+	if current < previous {
+		return current
+	}
+
+	return current - previous
+}
+
+func perSecond(count int, elapsed time.Duration) int {
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return int(math.Ceil(float64(count) / elapsed.Seconds()))
+}
+
+// Breakdown returns requests per second of each pod measured by the last check when requests counter is read
+func (p *Probe) Breakdown() map[string]int {
+	return p.podRates
+}
+
+// PodDeletionCosts returns active connections of each running pod, pods serving least connections should be removed first.
+// When requests counter is read, requests per second measured by the last check are returned instead.
+func (p *Probe) PodDeletionCosts(ctx context.Context) (map[string]int, error) {
+	if p.metric == nginx_stats.MetricRequests {
+		if len(p.podRates) == 0 {
+			return map[string]int{}, errors.New("requests per second of pods not measured yet")
+		}
+
+		return maps.Clone(p.podRates), nil
+	}
+
+	pods, err := p.k8sService.GetPodsFromDeployment(ctx, p.deployment, p.podSelector)
+	if err != nil {
+		return map[string]int{}, err
+	}
+
+	runningPods := &corev1.PodList{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning && pod.Status.PodIP != "" {
+			runningPods.Items = append(runningPods.Items, pod)
+		}
+	}
+
+	return p.fetchActiveConnectionsFromPods(runningPods)
+}
+
 type nginxStatsResult struct {
 	pod               corev1.Pod
 	activeConnections int
@@ -204,7 +367,12 @@ func (p *Probe) fetchActiveConnectionsFromPods(pods *corev1.PodList) (map[string
 		ctx, cancel := context.WithTimeout(context.Background(), p.requestTimeout)
 		defer cancel()
 
-		activeConnections, err := p.nginxClient.GetActiveConnections(ctx, pod.Status.PodIP)
+		var activeConnections int
+
+		address, err := PodAddress(pod, p.port)
+		if err == nil {
+			activeConnections, err = p.nginxClient.GetMetric(ctx, address)
+		}
 
 		zap.S().With("pod", pod.ObjectMeta.Name).Debugf("fetched active connections from pod: %+v", activeConnections)
 
@@ -231,3 +399,40 @@ func (p *Probe) fetchActiveConnectionsFromPods(pods *corev1.PodList) (map[string
 
 	return results, nil
 }
+
+// PodAddress returns IP of pod joined with given port, named port is looked up in containers of pod
+func PodAddress(pod corev1.Pod, port string) (string, error) {
+	if port == "" {
+		return pod.Status.PodIP, nil
+	}
+
+	if _, err := strconv.Atoi(port); err == nil {
+		return net.JoinHostPort(pod.Status.PodIP, port), nil
+	}
+
+	for _, container := range pod.Spec.Containers {
+		for _, containerPort := range container.Ports {
+			if containerPort.Name == port {
+				return net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(containerPort.ContainerPort))), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("pod %v has no container port named %v", pod.ObjectMeta.Name, port)
+}
+
+// Build returns TLS config verifying pods, nil when no option is set and system CAs are used
+func (tc TLSConfig) Build() (*tls.Config, error) {
+	if tc == (TLSConfig{}) {
+		return nil, nil
+	}
+
+	config, err := helper.NewTLSConfig(tc.CAFile, tc.InsecureSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	config.ServerName = tc.ServerName
+
+	return config, nil
+}
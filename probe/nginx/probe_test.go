@@ -5,10 +5,12 @@ import (
 	"errors"
 	"time"
 
+	"github.com/AirHelp/autoscaler/nginx_stats"
 	nginxMock "github.com/AirHelp/autoscaler/probe/nginx/mock"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -76,6 +78,8 @@ var _ = Describe("Probe", func() {
 			nginxClient: nginxClientMock,
 
 			deployment: deployment,
+
+			podSelector: defaultPodSelector,
 		}
 	})
 
@@ -89,18 +93,18 @@ var _ = Describe("Probe", func() {
 			probe.consecutiveReads = 3
 			probe.timeout = 100 * time.Millisecond
 
-			k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, deployment, additionalExpectedWebPodLabels).Return(pods, nil)
+			k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, deployment, defaultPodSelector).Return(pods, nil)
 
 			gomock.InOrder(
-				nginxClientMock.EXPECT().GetActiveConnections(gomock.Any(), "0.0.0.0").Return(25, nil),
-				nginxClientMock.EXPECT().GetActiveConnections(gomock.Any(), "0.0.0.0").Return(8, nil),
-				nginxClientMock.EXPECT().GetActiveConnections(gomock.Any(), "0.0.0.0").Return(40, nil),
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.0").Return(25, nil),
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.0").Return(8, nil),
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.0").Return(40, nil),
 			)
 
 			gomock.InOrder(
-				nginxClientMock.EXPECT().GetActiveConnections(gomock.Any(), "0.0.0.1").Return(2, nil),
-				nginxClientMock.EXPECT().GetActiveConnections(gomock.Any(), "0.0.0.1").Return(16, nil),
-				nginxClientMock.EXPECT().GetActiveConnections(gomock.Any(), "0.0.0.1").Return(60, nil),
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.1").Return(2, nil),
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.1").Return(16, nil),
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.1").Return(60, nil),
 			)
 		})
 		It("When statistic=average the end value is calculated properly", func() {
@@ -131,12 +135,159 @@ var _ = Describe("Probe", func() {
 		})
 	})
 
+	Context("When metric=requests", func() {
+		BeforeEach(func() {
+			probe.metric = nginx_stats.MetricRequests
+			probe.statistic = "maximum"
+			probe.consecutiveReads = 3
+			probe.timeout = time.Millisecond
+
+			// reads are made 100ms apart
+			readAt := time.Date(2020, 12, 14, 7, 45, 30, 0, time.UTC)
+			now = func() time.Time {
+				defer func() { readAt = readAt.Add(100 * time.Millisecond) }()
+				return readAt
+			}
+			DeferCleanup(func() { now = time.Now })
+
+			k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, deployment, defaultPodSelector).Return(pods, nil)
+
+			gomock.InOrder(
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.0").Return(1000, nil),
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.0").Return(1010, nil),
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.0").Return(1030, nil),
+			)
+
+			gomock.InOrder(
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.1").Return(500, nil),
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.1").Return(505, nil),
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.1").Return(505, nil),
+			)
+		})
+
+		It("Returns requests per second between consecutive reads", func() {
+			res, err := probe.Check(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(200))
+			Expect(probe.Breakdown()).To(Equal(map[string]int{"pod1": 150, "pod2": 25}))
+		})
+	})
+
+	Describe("requestRates()", func() {
+		start := time.Now()
+		readAt := []time.Time{start, start.Add(2 * time.Second), start.Add(4 * time.Second)}
+
+		It("Returns rates of all pods between reads and rate of each pod", func() {
+			rates, podRates := requestRates([]map[string]int{
+				{"pod1": 100, "pod2": 50},
+				{"pod1": 110, "pod2": 60},
+				{"pod1": 130, "pod2": 60},
+			}, readAt)
+
+			Expect(rates).To(Equal([]int{10, 10}))
+			Expect(podRates).To(Equal(map[string]int{"pod1": 8, "pod2": 3}))
+		})
+
+		It("Counts requests since restart when counter was reset", func() {
+			rates, podRates := requestRates([]map[string]int{
+				{"pod1": 100},
+				{"pod1": 10},
+				{"pod1": 30},
+			}, readAt)
+
+			Expect(rates).To(Equal([]int{5, 10}))
+			Expect(podRates).To(Equal(map[string]int{"pod1": 8}))
+		})
+
+		It("Returns no rates for single read", func() {
+			rates, podRates := requestRates([]map[string]int{{"pod1": 100}}, readAt[:1])
+
+			Expect(rates).To(BeEmpty())
+			Expect(podRates).To(BeEmpty())
+		})
+	})
+
+	DescribeTable("PodAddress()",
+		func(port, expected string) {
+			pod := v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: "app", Ports: []v1.ContainerPort{{Name: "http", ContainerPort: 3000}}},
+						{Name: "nginx", Ports: []v1.ContainerPort{{Name: "stats", ContainerPort: 8080}}},
+					},
+				},
+				Status: v1.PodStatus{PodIP: "10.0.0.1"},
+			}
+
+			Expect(PodAddress(pod, port)).To(Equal(expected))
+		},
+		Entry("Without port", "", "10.0.0.1"),
+		Entry("Port number", "9113", "10.0.0.1:9113"),
+		Entry("Named port", "stats", "10.0.0.1:8080"),
+	)
+
+	It("PodAddress() returns error for unknown named port", func() {
+		_, err := PodAddress(v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}}, "stats")
+
+		Expect(err).To(MatchError("pod pod1 has no container port named stats"))
+	})
+
+	It("Reads config", func() {
+		var config Config
+
+		raw := "pod_selector:\n  app: api\nport: 8080\nscheme: https\nheaders:\n  Authorization: Bearer secret\ntls:\n  server_name: api.internal"
+
+		Expect(yaml.Unmarshal([]byte(raw), &config)).To(Succeed())
+		Expect(config).To(Equal(Config{
+			PodSelector: map[string]string{"app": "api"},
+			Port:        "8080",
+			Scheme:      "https",
+			Headers:     map[string]string{"Authorization": "Bearer secret"},
+			TLS:         TLSConfig{ServerName: "api.internal"},
+		}))
+	})
+
+	Describe("New()", func() {
+		It("Requires many reads to compute request rate", func() {
+			_, err := New(&Config{Metric: nginx_stats.MetricRequests, ConsecutiveReads: 1}, k8sServiceMock, deployment)
+
+			Expect(err).To(MatchError("at least 2 consecutive reads are required to compute rate of requests"))
+		})
+
+		It("Selects web pods by default", func() {
+			probe, err := New(&Config{}, k8sServiceMock, deployment)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(probe.podSelector).To(Equal(map[string]string{"type": "web"}))
+		})
+
+		It("Selects all pods of deployment with empty selector", func() {
+			probe, err := New(&Config{PodSelector: map[string]string{}}, k8sServiceMock, deployment)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(probe.podSelector).To(BeEmpty())
+		})
+
+		It("Rejects missing CA file", func() {
+			_, err := New(&Config{Scheme: "https", TLS: TLSConfig{CAFile: "/nonexistent/ca.pem"}}, k8sServiceMock, deployment)
+
+			Expect(err).To(MatchError(ContainSubstring("failed to read CA file")))
+		})
+
+		It("Rejects unknown format", func() {
+			_, err := New(&Config{Format: "xml"}, k8sServiceMock, deployment)
+
+			Expect(err).To(MatchError(ContainSubstring("unknown format `xml`")))
+		})
+	})
+
 	Context("When fetching pods fails", func() {
 		var err error
 
 		BeforeEach(func() {
 			err = errors.New("Failed to fetch pods")
-			k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, deployment, additionalExpectedWebPodLabels).Return(&v1.PodList{}, err)
+			k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, deployment, defaultPodSelector).Return(&v1.PodList{}, err)
 		})
 
 		It("Returns 0 and error", func() {
@@ -151,7 +302,7 @@ var _ = Describe("Probe", func() {
 		BeforeEach(func() {
 			pods.Items[0].Status.Phase = v1.PodFailed
 
-			k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, deployment, additionalExpectedWebPodLabels).Return(pods, nil)
+			k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, deployment, defaultPodSelector).Return(pods, nil)
 		})
 
 		It("Returns 0 and error", func() {
@@ -166,7 +317,7 @@ var _ = Describe("Probe", func() {
 		BeforeEach(func() {
 			pods.Items[1].Status.Conditions[0].Status = v1.ConditionFalse
 
-			k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, deployment, additionalExpectedWebPodLabels).Return(pods, nil)
+			k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, deployment, defaultPodSelector).Return(pods, nil)
 		})
 
 		It("Returns 0 and error", func() {
@@ -182,16 +333,16 @@ var _ = Describe("Probe", func() {
 			probe.consecutiveReads = 2
 			probe.timeout = 100 * time.Millisecond
 
-			k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, deployment, additionalExpectedWebPodLabels).Return(pods, nil)
+			k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, deployment, defaultPodSelector).Return(pods, nil)
 
 			gomock.InOrder(
-				nginxClientMock.EXPECT().GetActiveConnections(gomock.Any(), "0.0.0.0").Return(25, nil),
-				nginxClientMock.EXPECT().GetActiveConnections(gomock.Any(), "0.0.0.0").Return(0, errors.New("Expected response: 200, got: 502")),
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.0").Return(25, nil),
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.0").Return(0, errors.New("Expected response: 200, got: 502")),
 			)
 
 			gomock.InOrder(
-				nginxClientMock.EXPECT().GetActiveConnections(gomock.Any(), "0.0.0.1").Return(2, nil),
-				nginxClientMock.EXPECT().GetActiveConnections(gomock.Any(), "0.0.0.1").Return(16, nil),
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.1").Return(2, nil),
+				nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.1").Return(16, nil),
 			)
 		})
 
@@ -202,4 +353,67 @@ var _ = Describe("Probe", func() {
 			Expect(resultErr).To(Equal(errors.New("Expected response: 200, got: 502")))
 		})
 	})
+
+	Describe("PodDeletionCosts()", func() {
+		It("Returns active connections of running pods", func() {
+			pods.Items = append(pods.Items, v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pod3",
+				},
+				Status: v1.PodStatus{
+					Phase: v1.PodPending,
+				},
+			})
+
+			k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, deployment, defaultPodSelector).Return(pods, nil)
+			nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.0").Return(25, nil)
+			nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.1").Return(2, nil)
+
+			res, err := probe.PodDeletionCosts(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[string]int{"pod1": 25, "pod2": 2}))
+		})
+
+		It("Returns requests per second measured by the last check when probe reads requests counter", func() {
+			probe.metric = nginx_stats.MetricRequests
+			probe.podRates = map[string]int{"pod1": 30, "pod2": 4}
+
+			res, err := probe.PodDeletionCosts(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[string]int{"pod1": 30, "pod2": 4}))
+		})
+
+		It("Returns error when requests per second weren't measured yet", func() {
+			probe.metric = nginx_stats.MetricRequests
+
+			_, err := probe.PodDeletionCosts(ctx)
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Reads stats of pods matching selector on given port", func() {
+			probe.podSelector = map[string]string{"app": "api"}
+			probe.port = "8080"
+
+			k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, deployment, map[string]string{"app": "api"}).Return(pods, nil)
+			nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.0:8080").Return(25, nil)
+			nginxClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.1:8080").Return(2, nil)
+
+			res, err := probe.PodDeletionCosts(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[string]int{"pod1": 25, "pod2": 2}))
+		})
+
+		It("Returns error when fetching pods fails", func() {
+			err := errors.New("Failed to fetch pods")
+			k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, deployment, defaultPodSelector).Return(&v1.PodList{}, err)
+
+			_, resultErr := probe.PodDeletionCosts(ctx)
+
+			Expect(resultErr).To(Equal(err))
+		})
+	})
 })
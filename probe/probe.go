@@ -7,3 +7,34 @@ type Probe interface {
 	Kind() string
 	Check(context.Context) (int, error)
 }
+
+// ReplicasProbe is a probe which result is turned directly into desired replicas instead of being divided by threshold
+type ReplicasProbe interface {
+	Probe
+	DesiredReplicas(int) int
+}
+
+// PodCostProbe knows how busy each pod of deployment is, it is used to pick pods to remove on scale down
+type PodCostProbe interface {
+	PodDeletionCosts(context.Context) (map[string]int, error)
+}
+
+// BreakdownProbe reports how the last check result was composed, eg. contribution of each queue
+type BreakdownProbe interface {
+	Breakdown() map[string]int
+}
+
+// PartialProbe reports parts of the last check which failed, result of such check covers only remaining parts
+type PartialProbe interface {
+	Failures() map[string]string
+}
+
+// LatencyProbe reports when work waits longer than allowed, deployment is scaled up then even when probe result doesn't require it
+type LatencyProbe interface {
+	LatencyBreach() string
+}
+
+// DeadLetterProbe monitors dead-letter queues, their depth is reported but never counted in probe result
+type DeadLetterProbe interface {
+	DeadLetterDepths() map[string]int
+}
@@ -0,0 +1,141 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultBullMQPrefix = "bull"
+	// bullMQDelayShift is multiplier of delayed job timestamp in its score, lower bits hold job counter
+	bullMQDelayShift = 0x1000
+)
+
+var ErrNoBullMQQueues = errors.New("bullmq queues cannot be empty")
+
+// BullMQConfig is config of probe reading BullMQ and Bull queues
+type BullMQConfig struct {
+	ConnectionConfig `yaml:",inline"`
+
+	// Prefix is queue prefix set in BullMQ, keys are named `<prefix>:<queue>:wait` etc.
+	Prefix string   `yaml:"prefix"`
+	Queues []string `yaml:"queues"`
+}
+
+// BullMQProbe counts jobs waiting, prioritized, being processed and delayed ones which are due. Paused queues count as zero,
+// as no worker consumes them.
+type BullMQProbe struct {
+	client redis.UniversalClient
+	prefix string
+	queues []string
+
+	breakdown map[string]int
+}
+
+// bullMQQueue holds commands reading one queue
+type bullMQQueue struct {
+	wait        *redis.IntCmd
+	active      *redis.IntCmd
+	prioritized *redis.IntCmd
+	delayed     *redis.IntCmd
+	paused      *redis.BoolCmd
+	// legacyPaused is set by Bull instead of paused field of meta hash
+	legacyPaused *redis.IntCmd
+}
+
+func NewBullMQ(config *BullMQConfig) (*BullMQProbe, error) {
+	if len(config.Queues) == 0 {
+		return &BullMQProbe{}, ErrNoBullMQQueues
+	}
+
+	c, err := connect(&config.ConnectionConfig)
+	if err != nil {
+		return &BullMQProbe{}, err
+	}
+
+	prefix := config.Prefix
+	if prefix == "" {
+		prefix = defaultBullMQPrefix
+	}
+
+	return &BullMQProbe{
+		client: c,
+		prefix: prefix,
+		queues: config.Queues,
+	}, nil
+}
+
+func (p *BullMQProbe) Kind() string {
+	return "bullmq"
+}
+
+func (p *BullMQProbe) Check(ctx context.Context) (int, error) {
+	// delayed jobs are due when their timestamp in milliseconds is in the past, whatever their counter is
+	dueAt := strconv.FormatInt(time.Now().UnixMilli()*bullMQDelayShift+bullMQDelayShift-1, 10)
+
+	queues := make([]bullMQQueue, len(p.queues))
+
+	_, err := p.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, name := range p.queues {
+			queues[i] = bullMQQueue{
+				wait:         pipe.LLen(ctx, p.key(name, "wait")),
+				active:       pipe.LLen(ctx, p.key(name, "active")),
+				prioritized:  pipe.ZCard(ctx, p.key(name, "prioritized")),
+				delayed:      pipe.ZCount(ctx, p.key(name, "delayed"), "-inf", dueAt),
+				paused:       pipe.HExists(ctx, p.key(name, "meta"), "paused"),
+				legacyPaused: pipe.Exists(ctx, p.key(name, "meta-paused")),
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to read bullmq queues: %w", err)
+	}
+
+	var acc int
+	breakdown := map[string]int{}
+
+	for i, name := range p.queues {
+		q := queues[i]
+
+		if q.paused.Val() || q.legacyPaused.Val() > 0 {
+			zap.S().Debugf("bullmq queue %v is paused, not counting it", name)
+			continue
+		}
+
+		jobs := map[string]int{
+			name + ":wait":        int(q.wait.Val()),
+			name + ":active":      int(q.active.Val()),
+			name + ":prioritized": int(q.prioritized.Val()),
+			name + ":delayed":     int(q.delayed.Val()),
+		}
+
+		for key, count := range jobs {
+			breakdown[key] = count
+			acc += count
+		}
+	}
+
+	zap.S().Debugf("bullmq jobs: %+v", breakdown)
+
+	p.breakdown = breakdown
+
+	return acc, nil
+}
+
+// Breakdown returns waiting, active, prioritized and due delayed jobs of each queue which isn't paused
+func (p *BullMQProbe) Breakdown() map[string]int {
+	return p.breakdown
+}
+
+func (p *BullMQProbe) key(queue, suffix string) string {
+	return p.prefix + ":" + queue + ":" + suffix
+}
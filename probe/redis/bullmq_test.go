@@ -0,0 +1,100 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BullMQProbe", func() {
+	var (
+		server *miniredis.Miniredis
+		ctx    = context.Background()
+	)
+
+	// seedQueue stores jobs the way BullMQ does, delayed ones scored with timestamp in milliseconds shifted by job counter
+	seedQueue := func(prefix, queue string) {
+		key := func(suffix string) string { return prefix + ":" + queue + ":" + suffix }
+		nowMs := float64(time.Now().UnixMilli())
+
+		_, _ = server.Lpush(key("wait"), "1")
+		_, _ = server.Lpush(key("wait"), "2")
+		_, _ = server.Lpush(key("active"), "3")
+		_, _ = server.ZAdd(key("prioritized"), 5, "4")
+		_, _ = server.ZAdd(key("delayed"), (nowMs-1000)*bullMQDelayShift+5, "5")
+		_, _ = server.ZAdd(key("delayed"), (nowMs+60000)*bullMQDelayShift+6, "6")
+	}
+
+	newProbe := func(config BullMQConfig) *BullMQProbe {
+		config.Hosts = []string{server.Addr()}
+
+		probe, err := NewBullMQ(&config)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(probe.client.Close)
+
+		return probe
+	}
+
+	BeforeEach(func() {
+		server = miniredis.RunT(GinkgoT())
+	})
+
+	It("Counts waiting, active, prioritized and due delayed jobs", func() {
+		seedQueue("bull", "emails")
+
+		probe := newProbe(BullMQConfig{Queues: []string{"emails"}})
+
+		res, err := probe.Check(ctx)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(5))
+		Expect(probe.Breakdown()).To(Equal(map[string]int{"emails:wait": 2, "emails:active": 1, "emails:prioritized": 1, "emails:delayed": 1}))
+	})
+
+	It("Reads queues with custom prefix", func() {
+		seedQueue("{jobs}", "emails")
+		seedQueue("{jobs}", "pdfs")
+
+		probe := newProbe(BullMQConfig{Prefix: "{jobs}", Queues: []string{"emails", "pdfs"}})
+
+		Expect(probe.Check(ctx)).To(Equal(10))
+	})
+
+	It("Counts paused queue as zero", func() {
+		seedQueue("bull", "emails")
+		seedQueue("bull", "pdfs")
+		server.HSet("bull:emails:meta", "paused", "1")
+
+		probe := newProbe(BullMQConfig{Queues: []string{"emails", "pdfs"}})
+
+		Expect(probe.Check(ctx)).To(Equal(5))
+		Expect(probe.Breakdown()).ToNot(HaveKey("emails:wait"))
+	})
+
+	It("Counts queue paused by Bull as zero", func() {
+		seedQueue("bull", "emails")
+		Expect(server.Set("bull:emails:meta-paused", "1")).To(Succeed())
+
+		probe := newProbe(BullMQConfig{Queues: []string{"emails"}})
+
+		Expect(probe.Check(ctx)).To(Equal(0))
+	})
+
+	It("Returns error when Redis can't be read", func() {
+		probe := newProbe(BullMQConfig{Queues: []string{"emails"}})
+		server.Close()
+
+		_, err := probe.Check(ctx)
+
+		Expect(err).To(MatchError(ContainSubstring("failed to read bullmq queues")))
+	})
+
+	It("Requires queues", func() {
+		_, err := NewBullMQ(&BullMQConfig{ConnectionConfig: ConnectionConfig{Hosts: []string{server.Addr()}}})
+
+		Expect(err).To(MatchError(ErrNoBullMQQueues))
+	})
+})
@@ -0,0 +1,158 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultCeleryQueue = "celery"
+	// celeryPrioritySeparator separates queue name and priority in names of priority lists, eg. `emails\x06\x163`
+	celeryPrioritySeparator = "\x06\x16"
+	celeryUnackedKey        = "unacked"
+)
+
+// defaultCeleryPrioritySteps are priority_steps of kombu Redis transport
+var defaultCeleryPrioritySteps = []int{0, 3, 6, 9}
+
+// CeleryConfig is config of probe reading Celery queues of Redis broker
+type CeleryConfig struct {
+	ConnectionConfig `yaml:",inline"`
+
+	Queues []string `yaml:"queues"`
+	// PrioritySteps are priority_steps transport option, each step other than 0 is kept in separate list
+	PrioritySteps []int `yaml:"priority_steps"`
+	// KeyPrefix is global_keyprefix transport option prefixing all keys
+	KeyPrefix      string `yaml:"key_prefix"`
+	IncludeUnacked bool   `yaml:"include_unacked"`
+}
+
+// CeleryProbe counts tasks waiting in queues summed over priority lists, optionally with tasks delivered to workers
+// but not acknowledged yet
+type CeleryProbe struct {
+	client         redis.UniversalClient
+	queues         []string
+	prioritySteps  []int
+	keyPrefix      string
+	includeUnacked bool
+
+	breakdown map[string]int
+}
+
+func NewCelery(config *CeleryConfig) (*CeleryProbe, error) {
+	c, err := connect(&config.ConnectionConfig)
+	if err != nil {
+		return &CeleryProbe{}, err
+	}
+
+	queues := config.Queues
+	if len(queues) == 0 {
+		queues = []string{defaultCeleryQueue}
+	}
+
+	steps := config.PrioritySteps
+	if len(steps) == 0 {
+		steps = defaultCeleryPrioritySteps
+	}
+
+	return &CeleryProbe{
+		client:         c,
+		queues:         queues,
+		prioritySteps:  steps,
+		keyPrefix:      config.KeyPrefix,
+		includeUnacked: config.IncludeUnacked,
+	}, nil
+}
+
+func (p *CeleryProbe) Kind() string {
+	return "celery"
+}
+
+func (p *CeleryProbe) Check(ctx context.Context) (int, error) {
+	lengths := make([][]*redis.IntCmd, len(p.queues))
+	var unacked *redis.StringSliceCmd
+
+	_, err := p.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, queue := range p.queues {
+			for _, step := range p.prioritySteps {
+				lengths[i] = append(lengths[i], pipe.LLen(ctx, p.keyPrefix+priorityQueue(queue, step)))
+			}
+		}
+
+		if p.includeUnacked {
+			unacked = pipe.HVals(ctx, p.keyPrefix+celeryUnackedKey)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to read celery queues: %w", err)
+	}
+
+	var acc int
+	breakdown := map[string]int{}
+
+	for i, queue := range p.queues {
+		for _, cmd := range lengths[i] {
+			breakdown[queue] += int(cmd.Val())
+		}
+
+		acc += breakdown[queue]
+	}
+
+	if unacked != nil {
+		breakdown[celeryUnackedKey] = p.countUnacked(unacked.Val())
+		acc += breakdown[celeryUnackedKey]
+	}
+
+	zap.S().Debugf("celery tasks: %+v", breakdown)
+
+	p.breakdown = breakdown
+
+	return acc, nil
+}
+
+// Breakdown returns tasks of each queue summed over priority lists and unacknowledged tasks of all queues
+func (p *CeleryProbe) Breakdown() map[string]int {
+	return p.breakdown
+}
+
+// countUnacked counts unacknowledged tasks of given queues. Each of them is stored as [message, exchange, routing key],
+// routing key equals queue name with default Celery routing.
+func (p *CeleryProbe) countUnacked(values []string) int {
+	var count int
+
+	for _, raw := range values {
+		var delivery []json.RawMessage
+		if err := json.Unmarshal([]byte(raw), &delivery); err != nil || len(delivery) < 3 {
+			zap.S().Debugf("skipping unacked celery task which can't be parsed: %v", raw)
+			continue
+		}
+
+		var routingKey string
+		if err := json.Unmarshal(delivery[2], &routingKey); err != nil {
+			continue
+		}
+
+		if slices.Contains(p.queues, routingKey) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// priorityQueue returns name of list keeping tasks of given priority step, step 0 is kept in list named after queue
+func priorityQueue(queue string, step int) string {
+	if step == 0 {
+		return queue
+	}
+
+	return fmt.Sprintf("%s%s%d", queue, celeryPrioritySeparator, step)
+}
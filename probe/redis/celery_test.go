@@ -0,0 +1,88 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/alicebob/miniredis/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CeleryProbe", func() {
+	var (
+		server *miniredis.Miniredis
+		ctx    = context.Background()
+	)
+
+	newProbe := func(config CeleryConfig) *CeleryProbe {
+		config.Hosts = []string{server.Addr()}
+
+		probe, err := NewCelery(&config)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(probe.client.Close)
+
+		return probe
+	}
+
+	BeforeEach(func() {
+		server = miniredis.RunT(GinkgoT())
+
+		_, _ = server.Lpush("emails", "t1")
+		_, _ = server.Lpush("emails\x06\x163", "t2")
+		_, _ = server.Lpush("emails\x06\x169", "t3")
+		_, _ = server.Lpush("reports", "t4")
+		server.HSet("unacked", "tag1", `[{"body":"..."}, "", "emails"]`)
+		server.HSet("unacked", "tag2", `[{"body":"..."}, "", "reports"]`)
+		server.HSet("unacked", "tag3", `[{"body":"..."}, "", "emails"]`)
+		server.HSet("unacked", "tag4", `not json`)
+	})
+
+	It("Sums tasks across priority lists of queue", func() {
+		probe := newProbe(CeleryConfig{Queues: []string{"emails"}})
+
+		res, err := probe.Check(ctx)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(3))
+		Expect(probe.Breakdown()).To(Equal(map[string]int{"emails": 3}))
+	})
+
+	It("Includes unacked tasks of given queues", func() {
+		probe := newProbe(CeleryConfig{Queues: []string{"emails"}, IncludeUnacked: true})
+
+		Expect(probe.Check(ctx)).To(Equal(5))
+		Expect(probe.Breakdown()).To(Equal(map[string]int{"emails": 3, "unacked": 2}))
+	})
+
+	It("Reads only given priority steps", func() {
+		probe := newProbe(CeleryConfig{Queues: []string{"emails"}, PrioritySteps: []int{0, 9}})
+
+		Expect(probe.Check(ctx)).To(Equal(2))
+	})
+
+	It("Reads keys with global prefix", func() {
+		_, _ = server.Lpush("myapp:emails", "t5")
+		server.HSet("myapp:unacked", "tag5", `[{}, "", "emails"]`)
+
+		probe := newProbe(CeleryConfig{Queues: []string{"emails"}, KeyPrefix: "myapp:", IncludeUnacked: true})
+
+		Expect(probe.Check(ctx)).To(Equal(2))
+	})
+
+	It("Checks default celery queue", func() {
+		_, _ = server.Lpush("celery", "t6")
+
+		probe := newProbe(CeleryConfig{})
+
+		Expect(probe.Check(ctx)).To(Equal(1))
+	})
+
+	It("Returns error when Redis can't be read", func() {
+		probe := newProbe(CeleryConfig{})
+		server.Close()
+
+		_, err := probe.Check(ctx)
+
+		Expect(err).To(MatchError(ContainSubstring("failed to read celery queues")))
+	})
+})
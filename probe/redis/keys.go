@@ -0,0 +1,215 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// Data types of keys, named as reported by Redis TYPE command
+const (
+	TypeList = "list"
+	TypeZSet = "zset"
+	TypeSet  = "set"
+	TypeHash = "hash"
+)
+
+// ScoreNow is replaced with current unix time in seconds when counting sorted set members
+const ScoreNow = "now"
+
+const (
+	defaultScanInterval = time.Minute
+	// scanCount is hint of how many keys SCAN looks at in one call
+	scanCount = 1000
+)
+
+// KeySpec selects key, or keys matching pattern, and how their size is read
+type KeySpec struct {
+	Type    string `yaml:"type"`
+	Key     string `yaml:"key"`
+	Pattern string `yaml:"pattern"`
+	// MinScore and MaxScore limit members of sorted set counted with ZCOUNT, eg. delayed jobs due now
+	MinScore string `yaml:"min_score"`
+	MaxScore string `yaml:"max_score"`
+}
+
+func (ks KeySpec) String() string {
+	if ks.Pattern != "" {
+		return fmt.Sprintf("%v pattern `%v`", ks.Type, ks.Pattern)
+	}
+
+	return fmt.Sprintf("%v key `%v`", ks.Type, ks.Key)
+}
+
+// label names key in breakdown, keys matching pattern are counted together under pattern
+func (ks KeySpec) label() string {
+	if ks.Pattern != "" {
+		return ks.Pattern
+	}
+
+	return ks.Key
+}
+
+// normalize fills defaults and checks spec is complete
+func (ks KeySpec) normalize() (KeySpec, error) {
+	if ks.Type == "" {
+		ks.Type = TypeList
+	}
+
+	if (ks.Key == "") == (ks.Pattern == "") {
+		return ks, fmt.Errorf("exactly one of key or pattern has to be given for %v key", ks.Type)
+	}
+
+	switch ks.Type {
+	case TypeList, TypeSet, TypeHash:
+		if ks.MinScore != "" || ks.MaxScore != "" {
+			return ks, fmt.Errorf("scores apply only to zset, not to %v", ks)
+		}
+	case TypeZSet:
+		if ks.MinScore == "" {
+			ks.MinScore = "-inf"
+		}
+
+		if ks.MaxScore == "" {
+			ks.MaxScore = "+inf"
+		}
+
+		for _, score := range []string{ks.MinScore, ks.MaxScore} {
+			if !isValidScore(score) {
+				return ks, fmt.Errorf("invalid score `%v` of %v, expected number, -inf, +inf or %v", score, ks, ScoreNow)
+			}
+		}
+	default:
+		return ks, fmt.Errorf("unknown key type `%v`, expected one of %v, %v, %v, %v", ks.Type, TypeList, TypeZSet, TypeSet, TypeHash)
+	}
+
+	return ks, nil
+}
+
+func isValidScore(score string) bool {
+	switch score {
+	case ScoreNow, "-inf", "+inf":
+		return true
+	}
+
+	_, err := strconv.ParseFloat(score, 64)
+	return err == nil
+}
+
+// resolveScore replaces ScoreNow with given time
+func resolveScore(score string, at time.Time) string {
+	if score == ScoreNow {
+		return strconv.FormatInt(at.Unix(), 10)
+	}
+
+	return score
+}
+
+// count queues command reading size of key of given spec
+func (ks KeySpec) count(ctx context.Context, pipe redis.Pipeliner, key string, at time.Time) *redis.IntCmd {
+	switch ks.Type {
+	case TypeZSet:
+		return pipe.ZCount(ctx, key, resolveScore(ks.MinScore, at), resolveScore(ks.MaxScore, at))
+	case TypeSet:
+		return pipe.SCard(ctx, key)
+	case TypeHash:
+		return pipe.HLen(ctx, key)
+	default:
+		return pipe.LLen(ctx, key)
+	}
+}
+
+// typedKey is key found on shard with spec telling how to read it
+type typedKey struct {
+	name string
+	spec KeySpec
+}
+
+// patternCache keeps keys found by SCAN, so patterns are scanned once per interval instead of on every check
+type patternCache struct {
+	mu       sync.Mutex
+	interval time.Duration
+	entries  map[string]scannedKeys
+}
+
+type scannedKeys struct {
+	keys      []string
+	scannedAt time.Time
+}
+
+func newPatternCache(interval time.Duration) *patternCache {
+	if interval <= 0 {
+		interval = defaultScanInterval
+	}
+
+	return &patternCache{
+		interval: interval,
+		entries:  map[string]scannedKeys{},
+	}
+}
+
+// keys returns keys of shard matching spec pattern, scanning shard when cached ones are outdated
+func (pc *patternCache) keys(ctx context.Context, shard redis.Cmdable, shardName string, spec KeySpec) ([]string, error) {
+	cacheKey := shardName + "/" + spec.Type + "/" + spec.Pattern
+
+	pc.mu.Lock()
+	entry, ok := pc.entries[cacheKey]
+	pc.mu.Unlock()
+
+	if ok && time.Since(entry.scannedAt) < pc.interval {
+		return entry.keys, nil
+	}
+
+	keys, err := scanKeys(ctx, shard, spec.Pattern, spec.Type)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %v: %w", spec, err)
+	}
+
+	zap.S().Debugf("found %d keys matching %v on redis %v", len(keys), spec, shardName)
+
+	pc.mu.Lock()
+	pc.entries[cacheKey] = scannedKeys{keys: keys, scannedAt: time.Now()}
+	pc.mu.Unlock()
+
+	return keys, nil
+}
+
+// scanKeys lists keys of given type matching pattern, in cluster mode every master is scanned
+func scanKeys(ctx context.Context, shard redis.Cmdable, pattern, keyType string) ([]string, error) {
+	if cluster, ok := shard.(*redis.ClusterClient); ok {
+		var (
+			mu   sync.Mutex
+			keys []string
+		)
+
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			found, err := scanKeys(ctx, node, pattern, keyType)
+
+			mu.Lock()
+			keys = append(keys, found...)
+			mu.Unlock()
+
+			return err
+		})
+
+		return keys, err
+	}
+
+	var keys []string
+
+	iter := shard.ScanType(ctx, 0, pattern, scanCount, keyType).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+
+	// SCAN may return key more than once
+	slices.Sort(keys)
+
+	return slices.Compact(keys), iter.Err()
+}
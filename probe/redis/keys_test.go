@@ -0,0 +1,143 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("Typed keys", func() {
+	var (
+		server *miniredis.Miniredis
+		ctx    = context.Background()
+	)
+
+	BeforeEach(func() {
+		server = miniredis.RunT(GinkgoT())
+	})
+
+	newProbe := func(keys ...KeySpec) *Probe {
+		probe, err := New(&Config{ConnectionConfig: ConnectionConfig{Hosts: []string{server.Addr()}}, Keys: keys})
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(probe.client.Close)
+
+		return probe
+	}
+
+	It("Counts members of sorted set due now", func() {
+		now := time.Now().Unix()
+		_, _ = server.ZAdd("delayed", float64(now-60), "due")
+		_, _ = server.ZAdd("delayed", float64(now-1), "due-too")
+		_, _ = server.ZAdd("delayed", float64(now+3600), "later")
+
+		probe := newProbe(KeySpec{Type: TypeZSet, Key: "delayed", MaxScore: ScoreNow})
+
+		Expect(probe.Check(ctx)).To(Equal(2))
+	})
+
+	It("Counts members of sets and fields of hashes", func() {
+		_, _ = server.SetAdd("workers", "w1", "w2")
+		server.HSet("in-progress", "job1", "w1")
+
+		probe := newProbe(KeySpec{Type: TypeSet, Key: "workers"}, KeySpec{Type: TypeHash, Key: "in-progress"})
+
+		Expect(probe.Check(ctx)).To(Equal(3))
+	})
+
+	It("Sums keys matching pattern together with list keys", func() {
+		_, _ = server.Lpush("queue:tenant:1", "a")
+		_, _ = server.Lpush("queue:tenant:2", "b")
+		_, _ = server.Lpush("queue:tenant:2", "c")
+		_, _ = server.SetAdd("queue:tenants", "1", "2")
+		_, _ = server.Lpush("emails", "d")
+
+		probe, err := New(&Config{
+			ConnectionConfig: ConnectionConfig{Hosts: []string{server.Addr()}},
+			ListKeys:         []string{"emails"},
+			Keys:             []KeySpec{{Pattern: "queue:*"}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer probe.client.Close()
+
+		Expect(probe.Check(ctx)).To(Equal(4))
+	})
+
+	It("Scans masters for pattern in cluster mode", func() {
+		_, _ = server.Lpush("queue:1", "a")
+		_, _ = server.Lpush("queue:2", "b")
+
+		probe, err := New(&Config{ConnectionConfig: ConnectionConfig{Mode: ModeCluster, Hosts: []string{server.Addr()}}, Keys: []KeySpec{{Pattern: "queue:*"}}})
+		Expect(err).ToNot(HaveOccurred())
+		defer probe.client.Close()
+
+		Expect(probe.Check(ctx)).To(Equal(2))
+	})
+
+	It("Reuses keys found for pattern until scan interval passes", func() {
+		_, _ = server.Lpush("queue:1", "a")
+
+		probe := newProbe(KeySpec{Type: TypeList, Pattern: "queue:*"})
+
+		Expect(probe.Check(ctx)).To(Equal(1))
+
+		_, _ = server.Lpush("queue:1", "b")
+		_, _ = server.Lpush("queue:2", "c")
+
+		Expect(probe.Check(ctx)).To(Equal(2))
+
+		probe.patterns.interval = 0
+
+		Expect(probe.Check(ctx)).To(Equal(3))
+	})
+
+	It("Returns error when shard can't be read", func() {
+		probe := newProbe(KeySpec{Type: TypeList, Pattern: "queue:*"})
+		server.Close()
+
+		_, err := probe.Check(ctx)
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("Rejects invalid key specs",
+		func(spec KeySpec, message string) {
+			_, err := New(&Config{ConnectionConfig: ConnectionConfig{Hosts: []string{server.Addr()}}, Keys: []KeySpec{spec}})
+
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("Without key nor pattern", KeySpec{Type: TypeSet}, "exactly one of key or pattern"),
+		Entry("With both key and pattern", KeySpec{Key: "jobs", Pattern: "jobs:*"}, "exactly one of key or pattern"),
+		Entry("Of unknown type", KeySpec{Type: "stream", Key: "jobs"}, "unknown key type `stream`"),
+		Entry("With scores of list", KeySpec{Key: "jobs", MaxScore: ScoreNow}, "scores apply only to zset"),
+		Entry("With invalid score", KeySpec{Type: TypeZSet, Key: "delayed", MinScore: "yesterday"}, "invalid score `yesterday`"),
+	)
+
+	DescribeTable("resolveScore()",
+		func(score, expected string) {
+			at := time.Unix(1700000000, 0)
+
+			Expect(resolveScore(score, at)).To(Equal(expected))
+		},
+		Entry("Now", ScoreNow, strconv.Itoa(1700000000)),
+		Entry("Infinity", "+inf", "+inf"),
+		Entry("Number", "12.5", "12.5"),
+	)
+
+	It("Reads key specs from config", func() {
+		var config Config
+
+		raw := "keys:\n  - {type: zset, key: delayed, max_score: now}\n  - {type: zset, key: scheduled, min_score: 0, max_score: 100}\n  - {pattern: \"queue:*\", type: list}"
+
+		Expect(yaml.Unmarshal([]byte(raw), &config)).To(Succeed())
+		Expect(config.Keys).To(Equal([]KeySpec{
+			{Type: TypeZSet, Key: "delayed", MaxScore: ScoreNow},
+			{Type: TypeZSet, Key: "scheduled", MinScore: "0", MaxScore: "100"},
+			{Type: TypeList, Pattern: "queue:*"},
+		}))
+	})
+})
@@ -2,86 +2,421 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
 
+	"github.com/AirHelp/autoscaler/helper"
 	"github.com/go-redis/redis/v8"
 	"go.uber.org/zap"
 )
 
+// Ways of connecting to Redis
+const (
+	// ModeRing spreads keys between independent instances
+	ModeRing = "ring"
+	// ModeCluster connects to Redis Cluster, eg. ElastiCache with cluster mode enabled
+	ModeCluster = "cluster"
+	// ModeSentinel connects to master found by Sentinel
+	ModeSentinel = "sentinel"
+	// ModeSingle connects to single instance
+	ModeSingle = "single"
+)
+
+// ConnectionConfig tells how to reach Redis, it's shared by all probes reading Redis
+type ConnectionConfig struct {
+	Mode  string   `yaml:"mode"`
+	Hosts []string `yaml:"hosts"`
+	// MasterName is name of master monitored by Sentinel, hosts are Sentinel addresses then
+	MasterName string `yaml:"master_name"`
+
+	Username string    `yaml:"username"`
+	Password string    `yaml:"password"`
+	DB       int       `yaml:"db"`
+	TLS      TLSConfig `yaml:"tls"`
+}
+
 type Config struct {
-	Hosts    []string `yaml:"hosts"`
-	ListKeys []string `yaml:"list_keys"`
+	ConnectionConfig `yaml:",inline"`
+
+	// ListKeys are checked like keys of list type
+	ListKeys []string  `yaml:"list_keys"`
+	Keys     []KeySpec `yaml:"keys"`
+	// ScanInterval is how long keys found for patterns are reused before scanning again
+	ScanInterval time.Duration `yaml:"scan_interval"`
+}
+
+// TLSConfig can be given as a plain bool or as a hash with options
+type TLSConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// CAFile is PEM encoded CA certificate used to verify server instead of system ones
+	CAFile             string `yaml:"ca_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
 }
 
+func (tc *TLSConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&tc.Enabled); err == nil {
+		return nil
+	}
+
+	// options imply TLS unless disabled explicitly
+	tc.Enabled = true
+
+	type plain TLSConfig
+	return unmarshal((*plain)(tc))
+}
+
+var ErrNoHosts = errors.New("hosts list cannot be empty")
+
 type Probe struct {
-	client   *redis.Ring
-	listKeys []string
+	client redis.UniversalClient
+	// name stands for the only shard of clients other than ring
+	name     string
+	keys     []KeySpec
+	patterns *patternCache
+
+	breakdown map[string]int
+	failures  map[string]string
 }
 
 func New(config *Config) (*Probe, error) {
 	if len(config.Hosts) == 0 {
-		return &Probe{}, fmt.Errorf("hosts list cannot be empty")
+		return &Probe{}, ErrNoHosts
 	}
 
-	if len(config.ListKeys) == 0 {
+	if len(config.ListKeys) == 0 && len(config.Keys) == 0 {
 		return &Probe{}, fmt.Errorf("list keys cannot be empty")
 	}
 
-	ringOpts := make(map[string]string)
+	keys, err := keySpecs(config)
+	if err != nil {
+		return &Probe{}, err
+	}
 
-	for i, addr := range config.Hosts {
-		key := fmt.Sprintf("host%d", i+1)
-		ringOpts[key] = addr
+	c, err := connect(&config.ConnectionConfig)
+	if err != nil {
+		return &Probe{}, err
 	}
 
-	c := redis.NewRing(&redis.RingOptions{
-		Addrs: ringOpts,
-	})
+	return &Probe{
+		client:   c,
+		name:     config.shardName(),
+		keys:     keys,
+		patterns: newPatternCache(config.ScanInterval),
+	}, nil
+}
 
-	err := c.ForEachShard(context.Background(), func(ctx context.Context, shard *redis.Client) error {
-		res := shard.Ping(ctx)
-		err := res.Err()
+// keySpecs merges list keys with typed keys
+func keySpecs(config *Config) ([]KeySpec, error) {
+	var specs []KeySpec
+
+	for _, key := range config.ListKeys {
+		specs = append(specs, KeySpec{Type: TypeList, Key: key})
+	}
 
+	for _, spec := range config.Keys {
+		spec, err := spec.normalize()
 		if err != nil {
-			zap.S().Errorf("failed to connect to Redis instance: %v", shard.Options().Addr)
-			return err
+			return nil, err
 		}
 
-		zap.S().Debugf("successfully connected to Redis instance: %v, result: %v", shard.Options().Addr, res.Val())
+		specs = append(specs, spec)
+	}
 
-		return nil
-	})
+	return specs, nil
+}
 
+// connect creates client and checks it reaches every node
+func connect(config *ConnectionConfig) (redis.UniversalClient, error) {
+	if len(config.Hosts) == 0 {
+		return nil, ErrNoHosts
+	}
+
+	c, err := newClient(config)
 	if err != nil {
-		return &Probe{}, err
+		return nil, err
 	}
 
-	return &Probe{
-		client:   c,
-		listKeys: config.ListKeys,
-	}, nil
+	if err := ping(context.Background(), c); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// newClient picks go-redis client matching mode
+func newClient(config *ConnectionConfig) (redis.UniversalClient, error) {
+	tlsConfig, err := config.TLS.build()
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.Mode {
+	case "", ModeRing:
+		ringOpts := make(map[string]string)
+
+		for i, addr := range config.Hosts {
+			key := fmt.Sprintf("host%d", i+1)
+			ringOpts[key] = addr
+		}
+
+		return redis.NewRing(&redis.RingOptions{
+			Addrs:     ringOpts,
+			Username:  config.Username,
+			Password:  config.Password,
+			DB:        config.DB,
+			TLSConfig: tlsConfig,
+		}), nil
+	case ModeCluster:
+		if config.DB != 0 {
+			return nil, fmt.Errorf("redis cluster supports only db 0")
+		}
+
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:     config.Hosts,
+			Username:  config.Username,
+			Password:  config.Password,
+			TLSConfig: tlsConfig,
+		}), nil
+	case ModeSentinel:
+		if config.MasterName == "" {
+			return nil, fmt.Errorf("master name is required in sentinel mode")
+		}
+
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    config.MasterName,
+			SentinelAddrs: config.Hosts,
+			Username:      config.Username,
+			Password:      config.Password,
+			DB:            config.DB,
+			TLSConfig:     tlsConfig,
+		}), nil
+	case ModeSingle:
+		if len(config.Hosts) > 1 {
+			return nil, fmt.Errorf("single mode accepts exactly one host, got %d", len(config.Hosts))
+		}
+
+		return redis.NewClient(&redis.Options{
+			Addr:      config.Hosts[0],
+			Username:  config.Username,
+			Password:  config.Password,
+			DB:        config.DB,
+			TLSConfig: tlsConfig,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown mode `%v`, expected one of %v, %v, %v, %v", config.Mode, ModeRing, ModeCluster, ModeSentinel, ModeSingle)
+	}
+}
+
+// shardName names the only shard of clients other than ring in breakdown and failures
+func (config *ConnectionConfig) shardName() string {
+	switch config.Mode {
+	case ModeCluster:
+		return ModeCluster
+	case ModeSentinel:
+		return config.MasterName
+	case ModeSingle:
+		return config.Hosts[0]
+	default:
+		return ""
+	}
+}
+
+func (tc TLSConfig) build() (*tls.Config, error) {
+	if !tc.Enabled {
+		return nil, nil
+	}
+
+	return helper.NewTLSConfig(tc.CAFile, tc.InsecureSkipVerify)
+}
+
+// ping checks connection to every node client uses
+func ping(ctx context.Context, client redis.UniversalClient) error {
+	switch c := client.(type) {
+	case *redis.Ring:
+		return c.ForEachShard(ctx, pingShard)
+	case *redis.ClusterClient:
+		return c.ForEachShard(ctx, pingShard)
+	case *redis.Client:
+		return pingShard(ctx, c)
+	default:
+		return c.Ping(ctx).Err()
+	}
+}
+
+func pingShard(ctx context.Context, shard *redis.Client) error {
+	res := shard.Ping(ctx)
+	err := res.Err()
+
+	if err != nil {
+		zap.S().Errorf("failed to connect to Redis instance: %v", shard.Options().Addr)
+		return err
+	}
+
+	zap.S().Debugf("successfully connected to Redis instance: %v, result: %v", shard.Options().Addr, res.Val())
+
+	return nil
 }
 
 func (p *Probe) Kind() string {
 	return "redis"
 }
 
+// Check reads every shard in one pipeline, shards are read concurrently. Shards which fail or are down are left out
+// of result and reported by Failures.
 func (p *Probe) Check(ctx context.Context) (int, error) {
-	var acc int
+	var (
+		mu      sync.Mutex
+		results = map[string]shardResult{}
+	)
 
-	for _, key := range p.listKeys {
-		err := p.client.ForEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
-			cmdRes := shard.LLen(ctx, key)
+	_ = p.forEachShard(ctx, func(ctx context.Context, name string, shard redis.Cmdable) error {
+		keys, err := p.checkShard(ctx, name, shard)
 
-			acc += int(cmdRes.Val())
+		mu.Lock()
+		results[name] = shardResult{keys: keys, err: err}
+		mu.Unlock()
 
-			return cmdRes.Err()
-		})
+		return nil
+	})
 
-		if err != nil {
-			return 0, err
+	var (
+		acc  int
+		errs []error
+	)
+
+	breakdown := map[string]int{}
+	failures := map[string]string{}
+
+	names := p.shardNames(results)
+
+	for _, name := range names {
+		result, ok := results[name]
+
+		switch {
+		case !ok:
+			// ring skips shards which don't respond to heartbeats
+			failures[name] = "shard is down"
+			errs = append(errs, fmt.Errorf("redis shard %v is down", name))
+			zap.S().Warnf("redis shard %v is down, not counting it", name)
+		case result.err != nil:
+			failures[name] = result.err.Error()
+			errs = append(errs, fmt.Errorf("failed to check redis shard %v: %w", name, result.err))
+			zap.S().With("error", result.err).Warnf("failed to check redis shard %v, not counting it", name)
+		default:
+			for key, count := range result.keys {
+				breakdown["key:"+key] += count
+				breakdown["shard:"+name] += count
+				acc += count
+			}
 		}
 	}
 
+	p.breakdown = breakdown
+	p.failures = failures
+
+	if len(failures) > 0 && len(failures) == len(names) {
+		return 0, errors.Join(errs...)
+	}
+
 	return acc, nil
 }
+
+// shardResult is count of each key read from shard, keys matching pattern are counted together under pattern
+type shardResult struct {
+	keys map[string]int
+	err  error
+}
+
+// shardNames returns names of all shards which should be checked, ring shards marked down are skipped by go-redis
+func (p *Probe) shardNames(results map[string]shardResult) []string {
+	names := slices.Collect(maps.Keys(results))
+
+	if ring, ok := p.client.(*redis.Ring); ok {
+		for _, addr := range ring.Options().Addrs {
+			if !slices.Contains(names, addr) {
+				names = append(names, addr)
+			}
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// Breakdown returns count of each key and of each shard read during the last check, keys matching pattern are counted
+// together under pattern
+func (p *Probe) Breakdown() map[string]int {
+	return p.breakdown
+}
+
+// Failures returns errors of shards which couldn't be read during the last check
+func (p *Probe) Failures() map[string]string {
+	return p.failures
+}
+
+// checkShard reads size of all keys of shard in one pipeline, keys matching patterns are found on that shard
+func (p *Probe) checkShard(ctx context.Context, name string, shard redis.Cmdable) (map[string]int, error) {
+	var keys []typedKey
+
+	for _, spec := range p.keys {
+		if spec.Pattern == "" {
+			keys = append(keys, typedKey{name: spec.Key, spec: spec})
+			continue
+		}
+
+		found, err := p.patterns.keys(ctx, shard, name, spec)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range found {
+			keys = append(keys, typedKey{name: key, spec: spec})
+		}
+	}
+
+	counts := map[string]int{}
+
+	if len(keys) == 0 {
+		return counts, nil
+	}
+
+	checkedAt := time.Now()
+	cmds := make([]*redis.IntCmd, len(keys))
+
+	_, err := shard.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = key.spec.count(ctx, pipe, key.name, checkedAt)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, cmd := range cmds {
+		counts[keys[i].spec.label()] += int(cmd.Val())
+	}
+
+	return counts, nil
+}
+
+// forEachShard runs fn on every ring shard concurrently. Cluster client routes each key to node owning it,
+// sentinel and single mode clients use one node, so fn runs once for them.
+func (p *Probe) forEachShard(ctx context.Context, fn func(context.Context, string, redis.Cmdable) error) error {
+	if ring, ok := p.client.(*redis.Ring); ok {
+		return ring.ForEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+			return fn(ctx, shard.Options().Addr, shard)
+		})
+	}
+
+	return fn(ctx, p.name, p.client)
+}
@@ -2,13 +2,26 @@ package redis
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/alicebob/miniredis/v2"
+	"gopkg.in/yaml.v2"
 )
 
 var _ = Describe("Probe", func() {
@@ -68,8 +81,8 @@ var _ = Describe("Probe", func() {
 				}
 
 				config = Config{
-					Hosts:    []string{server.Addr()},
-					ListKeys: []string{"asdf"},
+					ConnectionConfig: ConnectionConfig{Hosts: []string{server.Addr()}},
+					ListKeys:         []string{"asdf"},
 				}
 			})
 
@@ -82,21 +95,140 @@ var _ = Describe("Probe", func() {
 				probe, err := New(&config)
 
 				Expect(err).ToNot(HaveOccurred())
-				Expect(probe.listKeys).To(Equal(config.ListKeys))
+				Expect(probe.keys).To(Equal([]KeySpec{{Type: TypeList, Key: "asdf"}}))
 
 				pingRes, err := probe.client.Ping(context.Background()).Result()
 				Expect(err).ToNot(HaveOccurred())
 				Expect(pingRes).To(Equal("PONG"))
 			})
 		})
+
+		DescribeTable("Returns error on invalid connection config",
+			func(config Config, message string) {
+				config.ListKeys = []string{"asdf"}
+
+				_, err := New(&config)
+
+				Expect(err).To(MatchError(ContainSubstring(message)))
+			},
+			Entry("When mode is unknown", Config{ConnectionConfig: ConnectionConfig{Mode: "proxy", Hosts: []string{"host:6379"}}}, "unknown mode `proxy`"),
+			Entry("When sentinel has no master name", Config{ConnectionConfig: ConnectionConfig{Mode: ModeSentinel, Hosts: []string{"host:26379"}}}, "master name is required"),
+			Entry("When single mode has many hosts", Config{ConnectionConfig: ConnectionConfig{Mode: ModeSingle, Hosts: []string{"h1:6379", "h2:6379"}}}, "exactly one host"),
+			Entry("When cluster uses other db", Config{ConnectionConfig: ConnectionConfig{Mode: ModeCluster, Hosts: []string{"host:6379"}, DB: 1}}, "only db 0"),
+			Entry("When CA file is missing", Config{ConnectionConfig: ConnectionConfig{Hosts: []string{"host:6379"}, TLS: TLSConfig{Enabled: true, CAFile: "/nonexistent/ca.pem"}}}, "failed to read CA file"),
+		)
+
+		Context("When connecting in different modes", func() {
+			var (
+				server *miniredis.Miniredis
+				ctx    = context.Background()
+			)
+
+			AfterEach(func() {
+				server.Close()
+			})
+
+			// checkProbe pushes 2 jobs to key `jobs` of given db and expects probe to count them
+			checkProbe := func(config Config, db int) {
+				server.DB(db).Lpush("jobs", "1")
+				server.DB(db).Lpush("jobs", "2")
+
+				config.ListKeys = []string{"jobs"}
+				probe, err := New(&config)
+				Expect(err).ToNot(HaveOccurred())
+				defer probe.client.Close()
+
+				Expect(probe.Check(ctx)).To(Equal(2))
+			}
+
+			It("Authenticates with password and selects db in single mode", func() {
+				server = miniredis.RunT(GinkgoT())
+				server.RequireAuth("secret")
+
+				checkProbe(Config{ConnectionConfig: ConnectionConfig{Mode: ModeSingle, Hosts: []string{server.Addr()}, Password: "secret", DB: 3}}, 3)
+			})
+
+			It("Authenticates with ACL user in ring mode", func() {
+				server = miniredis.RunT(GinkgoT())
+				server.RequireUserAuth("autoscaler", "secret")
+
+				checkProbe(Config{ConnectionConfig: ConnectionConfig{Hosts: []string{server.Addr()}, Username: "autoscaler", Password: "secret"}}, 0)
+			})
+
+			It("Returns error when password is wrong", func() {
+				server = miniredis.RunT(GinkgoT())
+				server.RequireAuth("secret")
+
+				_, err := New(&Config{ConnectionConfig: ConnectionConfig{Mode: ModeSingle, Hosts: []string{server.Addr()}, Password: "wrong"}, ListKeys: []string{"jobs"}})
+
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("Counts keys in cluster mode", func() {
+				server = miniredis.RunT(GinkgoT())
+
+				checkProbe(Config{ConnectionConfig: ConnectionConfig{Mode: ModeCluster, Hosts: []string{server.Addr()}}}, 0)
+			})
+
+			It("Returns error when sentinel can't resolve master", func() {
+				// miniredis doesn't implement sentinel commands
+				server = miniredis.RunT(GinkgoT())
+
+				_, err := New(&Config{ConnectionConfig: ConnectionConfig{Mode: ModeSentinel, Hosts: []string{server.Addr()}, MasterName: "mymaster"}, ListKeys: []string{"jobs"}})
+
+				Expect(err).To(HaveOccurred())
+			})
+
+			Context("With TLS", func() {
+				var caFile string
+
+				BeforeEach(func() {
+					var cert tls.Certificate
+					cert, caFile = selfSignedCertificate()
+
+					var err error
+					server, err = miniredis.RunTLS(&tls.Config{Certificates: []tls.Certificate{cert}})
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				It("Verifies server with given CA", func() {
+					checkProbe(Config{ConnectionConfig: ConnectionConfig{Mode: ModeSingle, Hosts: []string{server.Addr()}, TLS: TLSConfig{Enabled: true, CAFile: caFile}}}, 0)
+				})
+
+				It("Skips verification when requested", func() {
+					checkProbe(Config{ConnectionConfig: ConnectionConfig{Hosts: []string{server.Addr()}, TLS: TLSConfig{Enabled: true, InsecureSkipVerify: true}}}, 0)
+				})
+
+				It("Returns error when server can't be verified", func() {
+					_, err := New(&Config{ConnectionConfig: ConnectionConfig{Mode: ModeSingle, Hosts: []string{server.Addr()}, TLS: TLSConfig{Enabled: true}}, ListKeys: []string{"jobs"}})
+
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+	})
+
+	Describe("Config", func() {
+		DescribeTable("Accepts tls given as bool and as hash",
+			func(raw string, expected TLSConfig) {
+				var config Config
+
+				Expect(yaml.Unmarshal([]byte(raw), &config)).To(Succeed())
+				Expect(config.TLS).To(Equal(expected))
+			},
+			Entry("Bool", "tls: true", TLSConfig{Enabled: true}),
+			Entry("Hash", "tls:\n  ca_file: /etc/ssl/redis.pem", TLSConfig{Enabled: true, CAFile: "/etc/ssl/redis.pem"}),
+			Entry("Disabled hash", "tls:\n  enabled: false\n  insecure_skip_verify: true", TLSConfig{InsecureSkipVerify: true}),
+			Entry("Missing", "mode: cluster", TLSConfig{}),
+		)
 	})
 
 	Describe("Probe receiver", func() {
 		var (
 			ctx context.Context
 
-			listKeys = []string{"k1", "k2", "k3"}
-			probe    Probe
+			keys  = []KeySpec{{Type: TypeList, Key: "k1"}, {Type: TypeList, Key: "k2"}, {Type: TypeList, Key: "k3"}}
+			probe Probe
 
 			ringClient *redis.Ring
 			server     *miniredis.Miniredis
@@ -119,8 +251,9 @@ var _ = Describe("Probe", func() {
 			})
 
 			probe = Probe{
-				listKeys: listKeys,
+				keys:     keys,
 				client:   ringClient,
+				patterns: newPatternCache(0),
 			}
 		})
 
@@ -168,4 +301,95 @@ var _ = Describe("Probe", func() {
 			})
 		})
 	})
+
+	Describe("Check() of many shards", func() {
+		var (
+			first, second *miniredis.Miniredis
+			probe         *Probe
+			ctx           = context.Background()
+		)
+
+		BeforeEach(func() {
+			first = miniredis.RunT(GinkgoT())
+			second = miniredis.RunT(GinkgoT())
+
+			var err error
+			probe, err = New(&Config{
+				ConnectionConfig: ConnectionConfig{Hosts: []string{first.Addr(), second.Addr()}},
+				ListKeys:         []string{"jobs"},
+				Keys:             []KeySpec{{Type: TypeSet, Pattern: "imports:*"}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(probe.client.Close)
+
+			_, _ = first.Lpush("jobs", "1")
+			_, _ = first.SetAdd("imports:a", "1", "2")
+			_, _ = second.Lpush("jobs", "2")
+			_, _ = second.Lpush("jobs", "3")
+			_, _ = second.SetAdd("imports:b", "1")
+		})
+
+		It("Reports count of each key and each shard", func() {
+			res, err := probe.Check(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(6))
+			Expect(probe.Breakdown()).To(Equal(map[string]int{
+				"key:jobs":               3,
+				"key:imports:*":          3,
+				"shard:" + first.Addr():  3,
+				"shard:" + second.Addr(): 3,
+			}))
+			Expect(probe.Failures()).To(BeEmpty())
+		})
+
+		It("Counts remaining shards and reports failed one", func() {
+			secondAddr := second.Addr()
+			second.Close()
+
+			res, err := probe.Check(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(3))
+			Expect(probe.Failures()).To(HaveKey(secondAddr))
+			Expect(probe.Breakdown()).ToNot(HaveKey("shard:" + secondAddr))
+		})
+
+		It("Returns error naming shards when none can be read", func() {
+			firstAddr, secondAddr := first.Addr(), second.Addr()
+			first.Close()
+			second.Close()
+
+			_, err := probe.Check(ctx)
+
+			Expect(err).To(MatchError(ContainSubstring(firstAddr)))
+			Expect(err).To(MatchError(ContainSubstring(secondAddr)))
+		})
+	})
 })
+
+// selfSignedCertificate returns certificate valid for 127.0.0.1 and path of PEM file with it, to be used as CA
+func selfSignedCertificate() (tls.Certificate, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "miniredis"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+
+	caFile := filepath.Join(GinkgoT().TempDir(), "ca.pem")
+	Expect(os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)).To(Succeed())
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, caFile
+}
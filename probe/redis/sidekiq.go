@@ -0,0 +1,235 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// SidekiqConfig is config of probe reading Sidekiq queues, jobs due in scheduled and retry sets and jobs being processed
+type SidekiqConfig struct {
+	ConnectionConfig `yaml:",inline"`
+
+	// Namespace is prefix of keys set with redis-namespace, eg. `myapp` for `myapp:queues`
+	Namespace string `yaml:"namespace"`
+	// Queues limits counted jobs to given queues, all queues are counted when empty
+	Queues       []string `yaml:"queues"`
+	IncludeRetry bool     `yaml:"include_retry"`
+}
+
+// SidekiqProbe counts enqueued and busy work of Sidekiq: jobs in queues, scheduled and retried jobs which are due,
+// and jobs being processed
+type SidekiqProbe struct {
+	client       redis.UniversalClient
+	namespace    string
+	queues       []string
+	includeRetry bool
+
+	breakdown map[string]int
+}
+
+// sidekiqJob is member of `schedule` and `retry` sets or value of `<identity>:work` hash field describing job
+// processed by one thread, only its queue is read
+type sidekiqJob struct {
+	Queue string `json:"queue"`
+}
+
+func NewSidekiq(config *SidekiqConfig) (*SidekiqProbe, error) {
+	c, err := connect(&config.ConnectionConfig)
+	if err != nil {
+		return &SidekiqProbe{}, err
+	}
+
+	return &SidekiqProbe{
+		client:       c,
+		namespace:    config.Namespace,
+		queues:       config.Queues,
+		includeRetry: config.IncludeRetry,
+	}, nil
+}
+
+func (p *SidekiqProbe) Kind() string {
+	return "sidekiq"
+}
+
+func (p *SidekiqProbe) Check(ctx context.Context) (int, error) {
+	queues, err := p.countedQueues(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read sidekiq queues: %w", err)
+	}
+
+	// Sidekiq scores scheduled and retried jobs with unix time in seconds when they are due
+	dueAt := strconv.FormatFloat(float64(time.Now().UnixNano())/float64(time.Second), 'f', 6, 64)
+
+	lengths := make([]*redis.IntCmd, len(queues))
+	var scheduled, retried func() int
+	var processes *redis.StringSliceCmd
+
+	_, err = p.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, queue := range queues {
+			lengths[i] = pipe.LLen(ctx, p.key("queue:"+queue))
+		}
+
+		scheduled = p.dueJobs(ctx, pipe, "schedule", dueAt, queues)
+		if p.includeRetry {
+			retried = p.dueJobs(ctx, pipe, "retry", dueAt, queues)
+		}
+
+		processes = pipe.SMembers(ctx, p.key("processes"))
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to read sidekiq jobs: %w", err)
+	}
+
+	breakdown := map[string]int{}
+
+	for i, queue := range queues {
+		breakdown["queue:"+queue] = int(lengths[i].Val())
+	}
+
+	breakdown["scheduled"] = scheduled()
+	if retried != nil {
+		breakdown["retry"] = retried()
+	}
+
+	busy, err := p.busy(ctx, processes.Val(), queues)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read busy sidekiq processes: %w", err)
+	}
+
+	breakdown["busy"] = busy
+
+	var acc int
+	for _, count := range breakdown {
+		acc += count
+	}
+
+	zap.S().Debugf("sidekiq jobs: %+v", breakdown)
+
+	p.breakdown = breakdown
+
+	return acc, nil
+}
+
+// Breakdown returns jobs of each queue, scheduled and retried jobs which are due and jobs being processed
+func (p *SidekiqProbe) Breakdown() map[string]int {
+	return p.breakdown
+}
+
+// countedQueues returns configured queues or all queues Sidekiq knows
+func (p *SidekiqProbe) countedQueues(ctx context.Context) ([]string, error) {
+	if len(p.queues) > 0 {
+		return p.queues, nil
+	}
+
+	queues, err := p.client.SMembers(ctx, p.key("queues")).Result()
+	slices.Sort(queues)
+
+	return queues, err
+}
+
+// dueJobs queues reading jobs of sorted set which are due, returned func counts them once pipeline is executed.
+// Jobs are counted with ZCOUNT when all queues are counted, otherwise queue of each job is read.
+func (p *SidekiqProbe) dueJobs(ctx context.Context, pipe redis.Pipeliner, key, dueAt string, queues []string) func() int {
+	if len(p.queues) == 0 {
+		cmd := pipe.ZCount(ctx, p.key(key), "-inf", dueAt)
+		return func() int { return int(cmd.Val()) }
+	}
+
+	cmd := pipe.ZRangeByScore(ctx, p.key(key), &redis.ZRangeBy{Min: "-inf", Max: dueAt})
+
+	return func() int {
+		var count int
+
+		for _, raw := range cmd.Val() {
+			if belongsTo(raw, queues) {
+				count++
+			}
+		}
+
+		return count
+	}
+}
+
+// belongsTo tells if job or work given as JSON is of one of queues, jobs which can't be parsed are counted too
+func belongsTo(raw string, queues []string) bool {
+	var job sidekiqJob
+	if err := json.Unmarshal([]byte(raw), &job); err != nil {
+		return true
+	}
+
+	return slices.Contains(queues, job.Queue)
+}
+
+// busy counts jobs being processed by live Sidekiq processes. Busy count of process is enough when all queues are
+// counted, otherwise queue of each job is read from work of process.
+func (p *SidekiqProbe) busy(ctx context.Context, identities []string, queues []string) (int, error) {
+	if len(identities) == 0 {
+		return 0, nil
+	}
+
+	busy := make([]*redis.StringCmd, len(identities))
+	work := make([]*redis.StringStringMapCmd, len(identities))
+
+	_, _ = p.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, identity := range identities {
+			if len(p.queues) == 0 {
+				busy[i] = pipe.HGet(ctx, p.key(identity), "busy")
+			} else {
+				work[i] = pipe.HGetAll(ctx, p.key(identity+":work"))
+			}
+		}
+
+		return nil
+	})
+
+	var count int
+
+	for i := range identities {
+		if len(p.queues) == 0 {
+			n, err := busy[i].Int()
+
+			// process which stopped sending heartbeats has no busy count anymore
+			if err == redis.Nil {
+				continue
+			}
+
+			if err != nil {
+				return 0, err
+			}
+
+			count += n
+			continue
+		}
+
+		if err := work[i].Err(); err != nil {
+			return 0, err
+		}
+
+		for _, raw := range work[i].Val() {
+			if belongsTo(raw, queues) {
+				count++
+			}
+		}
+	}
+
+	return count, nil
+}
+
+// key prefixes key with namespace
+func (p *SidekiqProbe) key(key string) string {
+	if p.namespace == "" {
+		return key
+	}
+
+	return p.namespace + ":" + key
+}
@@ -0,0 +1,126 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SidekiqProbe", func() {
+	var (
+		server *miniredis.Miniredis
+		ctx    = context.Background()
+	)
+
+	// seedSidekiq stores jobs the way Sidekiq does, with keys prefixed by namespace
+	seedSidekiq := func(namespace string) {
+		key := func(key string) string {
+			if namespace == "" {
+				return key
+			}
+
+			return namespace + ":" + key
+		}
+
+		now := float64(time.Now().Unix())
+
+		_, _ = server.SetAdd(key("queues"), "default", "mailers")
+		_, _ = server.Lpush(key("queue:default"), `{"class":"HardJob"}`)
+		_, _ = server.Lpush(key("queue:default"), `{"class":"HardJob"}`)
+		_, _ = server.Lpush(key("queue:mailers"), `{"class":"MailJob"}`)
+
+		_, _ = server.ZAdd(key("schedule"), now-10, `{"queue":"default","jid":"s1"}`)
+		_, _ = server.ZAdd(key("schedule"), now+3600, `{"queue":"default","jid":"s2"}`)
+		_, _ = server.ZAdd(key("retry"), now-10, `{"queue":"mailers","jid":"r1"}`)
+		_, _ = server.ZAdd(key("retry"), now-5, `{"queue":"default","jid":"r2"}`)
+
+		_, _ = server.SetAdd(key("processes"), "worker-1:1:abc", "worker-2:1:def", "gone:1:xyz")
+		server.HSet(key("worker-1:1:abc"), "busy", "2")
+		server.HSet(key("worker-2:1:def"), "busy", "1")
+		server.HSet(key("worker-1:1:abc:work"), "t1", `{"queue":"default","payload":"{}","run_at":1}`)
+		server.HSet(key("worker-1:1:abc:work"), "t2", `{"queue":"mailers","payload":"{}","run_at":1}`)
+		server.HSet(key("worker-2:1:def:work"), "t3", `{"queue":"default","payload":"{}","run_at":1}`)
+	}
+
+	newProbe := func(config SidekiqConfig) *SidekiqProbe {
+		config.Hosts = []string{server.Addr()}
+
+		probe, err := NewSidekiq(&config)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(probe.client.Close)
+
+		return probe
+	}
+
+	BeforeEach(func() {
+		server = miniredis.RunT(GinkgoT())
+	})
+
+	It("Counts enqueued, due scheduled and busy jobs of all queues", func() {
+		seedSidekiq("")
+
+		probe := newProbe(SidekiqConfig{})
+
+		res, err := probe.Check(ctx)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(7))
+		Expect(probe.Breakdown()).To(Equal(map[string]int{"queue:default": 2, "queue:mailers": 1, "scheduled": 1, "busy": 3}))
+	})
+
+	It("Counts due retries when requested", func() {
+		seedSidekiq("")
+
+		probe := newProbe(SidekiqConfig{IncludeRetry: true})
+
+		Expect(probe.Check(ctx)).To(Equal(9))
+		Expect(probe.Breakdown()).To(HaveKeyWithValue("retry", 2))
+	})
+
+	It("Counts only jobs of given queues", func() {
+		seedSidekiq("")
+
+		probe := newProbe(SidekiqConfig{Queues: []string{"mailers"}, IncludeRetry: true})
+
+		res, err := probe.Check(ctx)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(3))
+		Expect(probe.Breakdown()).To(Equal(map[string]int{"queue:mailers": 1, "scheduled": 0, "retry": 1, "busy": 1}))
+	})
+
+	It("Reads keys prefixed with namespace", func() {
+		seedSidekiq("myapp")
+
+		probe := newProbe(SidekiqConfig{Namespace: "myapp"})
+
+		Expect(probe.Check(ctx)).To(Equal(7))
+	})
+
+	It("Returns zero when Sidekiq has no data", func() {
+		probe := newProbe(SidekiqConfig{})
+
+		Expect(probe.Check(ctx)).To(Equal(0))
+	})
+
+	It("Returns error when Redis can't be read", func() {
+		probe := newProbe(SidekiqConfig{})
+		server.Close()
+
+		_, err := probe.Check(ctx)
+
+		Expect(err).To(MatchError(ContainSubstring("failed to read sidekiq queues")))
+	})
+
+	DescribeTable("belongsTo()",
+		func(raw string, expected bool) {
+			Expect(belongsTo(raw, []string{"default"})).To(Equal(expected))
+		},
+		Entry("Job of queue", `{"queue":"default"}`, true),
+		Entry("Job of other queue", `{"queue":"mailers"}`, false),
+		Entry("Invalid job", "not json", true),
+	)
+})
@@ -0,0 +1,267 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+
+	"github.com/AirHelp/autoscaler/helper"
+)
+
+const (
+	// undeliveredCountLimit caps counting of entries not delivered to group on Redis older than 7, which doesn't report group lag
+	undeliveredCountLimit = 10000
+	undeliveredPageSize   = 1000
+)
+
+var (
+	ErrNoStreams     = errors.New("streams cannot be empty")
+	ErrNoStreamGroup = errors.New("both stream and group have to be given")
+)
+
+// StreamConfig is config of probe reading backlog of consumer groups of Redis streams
+type StreamConfig struct {
+	ConnectionConfig `yaml:",inline"`
+
+	Streams []StreamGroup `yaml:"streams"`
+	// PendingDetails reports pending entries of each consumer and idle time of the oldest pending entry
+	PendingDetails bool `yaml:"pending_details"`
+}
+
+type StreamGroup struct {
+	Stream string `yaml:"stream"`
+	Group  string `yaml:"group"`
+}
+
+func (sg StreamGroup) String() string {
+	return sg.Stream + "/" + sg.Group
+}
+
+// groupInfo is part of XINFO GROUPS reply describing one group
+type groupInfo struct {
+	lastDeliveredID string
+	// lag is nil when Redis doesn't report it or can't tell it
+	lag *int64
+}
+
+// StreamProbe counts entries waiting for consumer groups: pending ones, delivered but not acknowledged yet,
+// and undelivered ones, added to stream after last entry delivered to group
+type StreamProbe struct {
+	client         redis.UniversalClient
+	streams        []StreamGroup
+	pendingDetails bool
+
+	breakdown map[string]int
+}
+
+func NewStream(config *StreamConfig) (*StreamProbe, error) {
+	if len(config.Streams) == 0 {
+		return &StreamProbe{}, ErrNoStreams
+	}
+
+	for _, sg := range config.Streams {
+		if sg.Stream == "" || sg.Group == "" {
+			return &StreamProbe{}, ErrNoStreamGroup
+		}
+	}
+
+	c, err := connect(&config.ConnectionConfig)
+	if err != nil {
+		return &StreamProbe{}, err
+	}
+
+	return &StreamProbe{
+		client:         c,
+		streams:        config.Streams,
+		pendingDetails: config.PendingDetails,
+	}, nil
+}
+
+func (p *StreamProbe) Kind() string {
+	return "redis_stream"
+}
+
+func (p *StreamProbe) Check(ctx context.Context) (int, error) {
+	var acc int
+	breakdown := map[string]int{}
+
+	for _, sg := range p.streams {
+		backlog, err := p.checkGroup(ctx, sg, breakdown)
+		if err != nil {
+			return 0, fmt.Errorf("failed to check redis stream group %v: %w", sg, err)
+		}
+
+		acc += backlog
+	}
+
+	p.breakdown = breakdown
+
+	return acc, nil
+}
+
+// Breakdown returns undelivered and pending entries of each group. When pending details are enabled pending ones are
+// given per consumer, along with seconds the oldest pending entry is idle for
+func (p *StreamProbe) Breakdown() map[string]int {
+	return p.breakdown
+}
+
+func (p *StreamProbe) checkGroup(ctx context.Context, sg StreamGroup, breakdown map[string]int) (int, error) {
+	group, err := p.groupInfo(ctx, sg)
+	if err != nil {
+		return 0, err
+	}
+
+	pending, err := p.client.XPending(ctx, sg.Stream, sg.Group).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	undelivered, err := p.undelivered(ctx, sg, group)
+	if err != nil {
+		return 0, err
+	}
+
+	breakdown[sg.String()+" undelivered"] = undelivered
+
+	zap.S().Debugf("redis stream group %v: %d pending, %d undelivered entries", sg, pending.Count, undelivered)
+
+	if !p.pendingDetails {
+		breakdown[sg.String()+" pending"] = int(pending.Count)
+		return int(pending.Count) + undelivered, nil
+	}
+
+	consumers := make(map[string]int, len(pending.Consumers))
+	for consumer, count := range pending.Consumers {
+		consumers[consumer] = int(count)
+		breakdown[sg.String()+" pending by "+consumer] = int(count)
+	}
+
+	if pending.Count > 0 {
+		idle, err := p.readOldestPendingIdle(ctx, sg)
+		if err != nil {
+			return 0, err
+		}
+
+		breakdown[sg.String()+" pending_idle_seconds"] = int(idle.Seconds())
+
+		zap.S().Debugf("redis stream group %v: oldest pending entry idle for %v, pending entries per consumer: %v", sg, idle, helper.IntMapToString(consumers))
+	}
+
+	return int(pending.Count) + undelivered, nil
+}
+
+// groupInfo reads XINFO GROUPS reply directly, as its shape changed in Redis 7
+func (p *StreamProbe) groupInfo(ctx context.Context, sg StreamGroup) (groupInfo, error) {
+	cmd := redis.NewSliceCmd(ctx, "xinfo", "groups", sg.Stream)
+	cmd.SetFirstKeyPos(2)
+
+	if err := p.client.Process(ctx, cmd); err != nil {
+		return groupInfo{}, err
+	}
+
+	for _, reply := range cmd.Val() {
+		fields, ok := reply.([]interface{})
+		if !ok {
+			return groupInfo{}, fmt.Errorf("unexpected XINFO GROUPS reply %v", reply)
+		}
+
+		values := make(map[string]interface{}, len(fields)/2)
+		for i := 0; i+1 < len(fields); i += 2 {
+			values[fmt.Sprint(fields[i])] = fields[i+1]
+		}
+
+		if values["name"] != sg.Group {
+			continue
+		}
+
+		group := groupInfo{lastDeliveredID: fmt.Sprint(values["last-delivered-id"])}
+
+		// lag is reliable only when Redis knows how many entries group has read
+		lag, ok := values["lag"].(int64)
+		if ok && values["entries-read"] != nil {
+			group.lag = &lag
+		}
+
+		return group, nil
+	}
+
+	return groupInfo{}, fmt.Errorf("group `%v` not found", sg.Group)
+}
+
+// undelivered returns group lag reported by Redis, or counts entries after last delivered one when lag is unknown
+func (p *StreamProbe) undelivered(ctx context.Context, sg StreamGroup, group groupInfo) (int, error) {
+	if group.lag != nil {
+		return int(*group.lag), nil
+	}
+
+	var count int
+
+	start, err := nextStreamID(group.lastDeliveredID)
+	if err != nil {
+		return 0, err
+	}
+
+	for count < undeliveredCountLimit {
+		messages, err := p.client.XRangeN(ctx, sg.Stream, start, "+", undeliveredPageSize).Result()
+		if err != nil {
+			return 0, err
+		}
+
+		count += len(messages)
+
+		if len(messages) < undeliveredPageSize {
+			return count, nil
+		}
+
+		if start, err = nextStreamID(messages[len(messages)-1].ID); err != nil {
+			return 0, err
+		}
+	}
+
+	zap.S().Warnf("redis stream group %v has at least %d undelivered entries, not counting more", sg, count)
+
+	return count, nil
+}
+
+// readOldestPendingIdle returns time since the oldest pending entry was delivered
+func (p *StreamProbe) readOldestPendingIdle(ctx context.Context, sg StreamGroup) (time.Duration, error) {
+	entries, err := p.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: sg.Stream,
+		Group:  sg.Group,
+		Start:  "-",
+		End:    "+",
+		Count:  1,
+	}).Result()
+
+	if err != nil || len(entries) == 0 {
+		return 0, err
+	}
+
+	return entries[0].Idle, nil
+}
+
+// nextStreamID returns the lowest ID greater than given one, eg. 1700000000000-1 for 1700000000000-0.
+// ID without sequence number, eg. 0, stands for sequence number 0.
+func nextStreamID(id string) (string, error) {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		seq = "0"
+	}
+
+	if _, err := strconv.ParseUint(ms, 10, 64); err != nil {
+		return "", fmt.Errorf("invalid stream ID `%v`: %w", id, err)
+	}
+
+	seqNumber, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid stream ID `%v`: %w", id, err)
+	}
+
+	return fmt.Sprintf("%s-%d", ms, seqNumber+1), nil
+}
@@ -0,0 +1,188 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("StreamProbe", func() {
+	var (
+		server *miniredis.Miniredis
+		client *redis.Client
+		ctx    = context.Background()
+	)
+
+	BeforeEach(func() {
+		server = miniredis.RunT(GinkgoT())
+		client = redis.NewClient(&redis.Options{Addr: server.Addr()})
+		DeferCleanup(client.Close)
+	})
+
+	addEntries := func(stream string, count int) {
+		for i := 0; i < count; i++ {
+			Expect(client.XAdd(ctx, &redis.XAddArgs{Stream: stream, Values: map[string]interface{}{"job": i}}).Err()).To(Succeed())
+		}
+	}
+
+	// readEntries delivers entries to consumer and returns their IDs
+	readEntries := func(stream, group, consumer string, count int64) []string {
+		streams, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: consumer,
+			Streams:  []string{stream, ">"},
+			Count:    count,
+			Block:    -1,
+		}).Result()
+		Expect(err).ToNot(HaveOccurred())
+
+		var ids []string
+		for _, message := range streams[0].Messages {
+			ids = append(ids, message.ID)
+		}
+
+		return ids
+	}
+
+	newProbe := func(config StreamConfig) *StreamProbe {
+		config.Hosts = []string{server.Addr()}
+
+		probe, err := NewStream(&config)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(probe.client.Close)
+
+		return probe
+	}
+
+	It("Counts pending and undelivered entries of group", func() {
+		addEntries("orders", 5)
+		Expect(client.XGroupCreate(ctx, "orders", "workers", "0").Err()).To(Succeed())
+		ids := readEntries("orders", "workers", "worker-1", 3)
+		Expect(client.XAck(ctx, "orders", "workers", ids[0]).Err()).To(Succeed())
+
+		probe := newProbe(StreamConfig{Streams: []StreamGroup{{Stream: "orders", Group: "workers"}}})
+
+		res, err := probe.Check(ctx)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(4))
+		Expect(probe.Breakdown()).To(Equal(map[string]int{"orders/workers pending": 2, "orders/workers undelivered": 2}))
+	})
+
+	It("Counts whole stream for group which received nothing yet", func() {
+		addEntries("orders", 3)
+		Expect(client.XGroupCreate(ctx, "orders", "workers", "0").Err()).To(Succeed())
+
+		probe := newProbe(StreamConfig{Streams: []StreamGroup{{Stream: "orders", Group: "workers"}}})
+
+		Expect(probe.Check(ctx)).To(Equal(3))
+	})
+
+	It("Counts undelivered entries over many pages", func() {
+		addEntries("orders", undeliveredPageSize+10)
+		Expect(client.XGroupCreate(ctx, "orders", "workers", "0").Err()).To(Succeed())
+		readEntries("orders", "workers", "worker-1", 5)
+
+		probe := newProbe(StreamConfig{Streams: []StreamGroup{{Stream: "orders", Group: "workers"}}})
+
+		Expect(probe.Check(ctx)).To(Equal(undeliveredPageSize + 10))
+	})
+
+	It("Reports pending entries of each consumer", func() {
+		addEntries("orders", 4)
+		Expect(client.XGroupCreate(ctx, "orders", "workers", "0").Err()).To(Succeed())
+		readEntries("orders", "workers", "worker-1", 1)
+		readEntries("orders", "workers", "worker-2", 2)
+		server.SetTime(time.Now().Add(90 * time.Second))
+
+		probe := newProbe(StreamConfig{Streams: []StreamGroup{{Stream: "orders", Group: "workers"}}, PendingDetails: true})
+
+		res, err := probe.Check(ctx)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(4))
+		Expect(probe.Breakdown()).To(Equal(map[string]int{
+			"orders/workers pending by worker-1":  1,
+			"orders/workers pending by worker-2":  2,
+			"orders/workers pending_idle_seconds": 90,
+			"orders/workers undelivered":          1,
+		}))
+	})
+
+	It("Reads idle time of the oldest pending entry only with pending details", func() {
+		addEntries("orders", 1)
+		Expect(client.XGroupCreate(ctx, "orders", "workers", "0").Err()).To(Succeed())
+		readEntries("orders", "workers", "worker-1", 1)
+
+		probe := newProbe(StreamConfig{Streams: []StreamGroup{{Stream: "orders", Group: "workers"}}})
+
+		Expect(probe.Check(ctx)).To(Equal(1))
+		Expect(probe.Breakdown()).ToNot(HaveKey("orders/workers pending_idle_seconds"))
+	})
+
+	It("Sums all groups", func() {
+		addEntries("orders", 2)
+		addEntries("emails", 1)
+		Expect(client.XGroupCreate(ctx, "orders", "workers", "0").Err()).To(Succeed())
+		Expect(client.XGroupCreate(ctx, "emails", "workers", "0").Err()).To(Succeed())
+
+		probe := newProbe(StreamConfig{Streams: []StreamGroup{{Stream: "orders", Group: "workers"}, {Stream: "emails", Group: "workers"}}})
+
+		Expect(probe.Check(ctx)).To(Equal(3))
+	})
+
+	It("Returns error when group doesn't exist", func() {
+		addEntries("orders", 1)
+
+		probe := newProbe(StreamConfig{Streams: []StreamGroup{{Stream: "orders", Group: "workers"}}})
+
+		_, err := probe.Check(ctx)
+
+		Expect(err).To(MatchError(ContainSubstring("group `workers` not found")))
+	})
+
+	DescribeTable("Rejects invalid config",
+		func(config StreamConfig, expected error) {
+			config.Hosts = []string{server.Addr()}
+
+			_, err := NewStream(&config)
+
+			Expect(err).To(MatchError(expected))
+		},
+		Entry("Without streams", StreamConfig{}, ErrNoStreams),
+		Entry("Without group", StreamConfig{Streams: []StreamGroup{{Stream: "orders"}}}, ErrNoStreamGroup),
+	)
+
+	DescribeTable("nextStreamID()",
+		func(id, expected string) {
+			Expect(nextStreamID(id)).To(Equal(expected))
+		},
+		Entry("Never delivered", "0-0", "0-1"),
+		Entry("Entry ID", "1700000000000-41", "1700000000000-42"),
+		Entry("Without sequence number", "0", "0-1"),
+	)
+
+	It("Rejects invalid stream ID", func() {
+		_, err := nextStreamID("last")
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Reads config", func() {
+		var config StreamConfig
+
+		raw := "hosts:\n  - redis:6379\nmode: single\nstreams:\n  - stream: orders\n    group: workers\npending_details: true"
+
+		Expect(yaml.Unmarshal([]byte(raw), &config)).To(Succeed())
+		Expect(config).To(Equal(StreamConfig{
+			ConnectionConfig: ConnectionConfig{Mode: ModeSingle, Hosts: []string{"redis:6379"}},
+			Streams:          []StreamGroup{{Stream: "orders", Group: "workers"}},
+			PendingDetails:   true,
+		}))
+	})
+})
@@ -0,0 +1,199 @@
+package sqs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.uber.org/zap"
+)
+
+// deadLetters monitors dead-letter queues, their messages never count toward scaling as more pods won't drain them
+type deadLetters struct {
+	listed   []*queue
+	redriven []*queue
+
+	// discover enables finding dead-letter queues from redrive policies of counted queues
+	discover   bool
+	interval   time.Duration
+	resolvedAt time.Time
+
+	depths map[string]int
+}
+
+// redrivePolicy is value of queue RedrivePolicy attribute
+type redrivePolicy struct {
+	DeadLetterTargetArn string `json:"deadLetterTargetArn"`
+}
+
+func newDeadLetters(config *Config) (*deadLetters, error) {
+	dl := &deadLetters{
+		discover: config.DiscoverDeadLetterQueues,
+		interval: config.ResolveInterval,
+	}
+
+	if dl.interval <= 0 {
+		dl.interval = defaultResolveInterval
+	}
+
+	for _, name := range config.DeadLetterQueues {
+		if name == "" {
+			return nil, ErrNoQueueName
+		}
+
+		// URLs are resolved on check, so missing dead-letter queue doesn't prevent autoscaler from starting
+		dl.listed = append(dl.listed, newDeadLetterQueue(name, ""))
+	}
+
+	return dl, nil
+}
+
+func newDeadLetterQueue(name, owner string) *queue {
+	return &queue{
+		name:       name,
+		owner:      owner,
+		attributes: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages},
+		weight:     1,
+	}
+}
+
+// refreshRedrivenQueues resolves dead-letter queues from redrive policies again once resolve interval passes
+func (p *Probe) refreshRedrivenQueues(ctx context.Context) {
+	dl := p.deadLetters
+
+	if !dl.discover || time.Since(dl.resolvedAt) < dl.interval {
+		return
+	}
+
+	if err := p.resolveRedrivenQueues(ctx); err != nil {
+		zap.S().With("error", err).Warn("failed to resolve dead-letter queues from redrive policies, keeping previous ones")
+	}
+}
+
+// checkDeadLetters reads depth of dead-letter queues, queues which can't be read are left out
+func (p *Probe) checkDeadLetters(ctx context.Context) {
+	dl := p.deadLetters
+
+	queues := append(slices.Clip(dl.listed), dl.redriven...)
+	results := p.readQueues(ctx, queues)
+	depths := make(map[string]int, len(queues))
+
+	for i, q := range queues {
+		if results[i].err != nil {
+			zap.S().With("error", results[i].err).Warnf("failed to read dead-letter queue %v", q.name)
+			continue
+		}
+
+		depths[q.name] = int(math.Ceil(results[i].value))
+	}
+
+	dl.depths = depths
+}
+
+// resolveRedrivenQueues finds dead-letter queues set in redrive policies of counted queues
+func (p *Probe) resolveRedrivenQueues(ctx context.Context) error {
+	dl := p.deadLetters
+
+	known := map[string]*queue{}
+	for _, q := range dl.redriven {
+		known[q.owner+"/"+q.name] = q
+	}
+
+	var redriven []*queue
+
+	for _, q := range p.countedQueues() {
+		if q.url == "" {
+			continue
+		}
+
+		output, err := p.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+			QueueUrl:       aws.String(q.url),
+			AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameRedrivePolicy},
+		})
+
+		var notExists *types.QueueDoesNotExist
+		if errors.As(err, &notExists) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		policy := output.Attributes[string(types.QueueAttributeNameRedrivePolicy)]
+		if policy == "" {
+			continue
+		}
+
+		var rp redrivePolicy
+		if err := json.Unmarshal([]byte(policy), &rp); err != nil {
+			return fmt.Errorf("invalid redrive policy of queue `%v`: %w", q.name, err)
+		}
+
+		name, owner, err := parseQueueARN(rp.DeadLetterTargetArn)
+		if err != nil {
+			return fmt.Errorf("invalid redrive policy of queue `%v`: %w", q.name, err)
+		}
+
+		if p.isDeadLetterMonitored(name, redriven) {
+			continue
+		}
+
+		dlq, ok := known[owner+"/"+name]
+		if !ok {
+			dlq = newDeadLetterQueue(name, owner)
+			zap.S().Infof("monitoring dead-letter queue %v of sqs queue %v", name, q.name)
+		}
+
+		redriven = append(redriven, dlq)
+	}
+
+	dl.redriven = redriven
+	dl.resolvedAt = time.Now()
+
+	// dead-letter queue may match discovery prefix or tags of queues it serves
+	p.discovered = slices.DeleteFunc(p.discovered, func(q *queue) bool {
+		if !p.isDeadLetterMonitored(q.name, redriven) {
+			return false
+		}
+
+		zap.S().Infof("sqs queue %v is dead-letter queue, not counting it", q.name)
+		return true
+	})
+
+	return nil
+}
+
+// isDeadLetterMonitored tells if queue is already listed explicitly or set as dead-letter queue of other queue
+func (p *Probe) isDeadLetterMonitored(name string, redriven []*queue) bool {
+	monitored := func(q *queue) bool { return q.name == name }
+
+	return slices.ContainsFunc(p.deadLetters.listed, monitored) || slices.ContainsFunc(redriven, monitored)
+}
+
+// parseQueueARN returns name and owner account of queue, eg. arn:aws:sqs:eu-west-1:123456789012:emails-dlq
+func parseQueueARN(arn string) (name, owner string, err error) {
+	parts := strings.Split(arn, ":")
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "sqs" {
+		return "", "", fmt.Errorf("`%v` is not sqs queue ARN", arn)
+	}
+
+	return parts[5], parts[4], nil
+}
+
+// DeadLetterDepths returns messages count of dead-letter queues read during the last check
+func (p *Probe) DeadLetterDepths() map[string]int {
+	if p.deadLetters == nil {
+		return nil
+	}
+
+	return p.deadLetters.depths
+}
@@ -0,0 +1,140 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AirHelp/autoscaler/probe/sqs/mocks"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+)
+
+var _ = Describe("Dead-letter queues", func() {
+	var (
+		mockCtrl *gomock.Controller
+		mockSqs  *sqsMock.MockSqsClient
+		config   *Config
+
+		ctx = context.Background()
+	)
+
+	expectMessages := func(url, messages string) {
+		mockSqs.EXPECT().GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+			QueueUrl:       aws.String(url),
+			AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages},
+		}).Return(&sqs.GetQueueAttributesOutput{Attributes: map[string]string{"ApproximateNumberOfMessages": messages}}, nil)
+	}
+
+	expectRedrivePolicy := func(url, policy string) {
+		mockSqs.EXPECT().GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+			QueueUrl:       aws.String(url),
+			AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameRedrivePolicy},
+		}).Return(&sqs.GetQueueAttributesOutput{Attributes: map[string]string{"RedrivePolicy": policy}}, nil)
+	}
+
+	newProbe := func() *Probe {
+		mockSqs.EXPECT().GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("emails")}).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("https://sqs/emails")}, nil)
+		mockSqs.EXPECT().GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("pdfs")}).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("https://sqs/pdfs")}, nil)
+
+		probe, err := New(ctx, config, &SQSService{Client: mockSqs}, 0)
+		Expect(err).ToNot(HaveOccurred())
+
+		return probe
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockSqs = sqsMock.NewMockSqsClient(mockCtrl)
+
+		config = &Config{
+			Queues:     []QueueConfig{{Name: "emails"}, {Name: "pdfs"}},
+			Attributes: []string{AttributeVisible},
+		}
+	})
+
+	It("Reports depth of listed dead-letter queues without counting it", func() {
+		config.DeadLetterQueues = []string{"emails-dlq"}
+		probe := newProbe()
+
+		expectMessages("https://sqs/emails", "1")
+		expectMessages("https://sqs/pdfs", "2")
+		mockSqs.EXPECT().GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("emails-dlq")}).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("https://sqs/emails-dlq")}, nil)
+		expectMessages("https://sqs/emails-dlq", "40")
+
+		res, err := probe.Check(ctx)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(3))
+		Expect(probe.Breakdown()).ToNot(HaveKey("emails-dlq"))
+		Expect(probe.DeadLetterDepths()).To(Equal(map[string]int{"emails-dlq": 40}))
+	})
+
+	It("Discovers dead-letter queues from redrive policies once per resolve interval", func() {
+		config.DiscoverDeadLetterQueues = true
+		config.DeadLetterQueues = []string{"pdfs-dlq"}
+		probe := newProbe()
+
+		expectMessages("https://sqs/emails", "1")
+		expectMessages("https://sqs/pdfs", "2")
+		expectRedrivePolicy("https://sqs/emails", `{"deadLetterTargetArn":"arn:aws:sqs:eu-west-1:210987654321:emails-dlq","maxReceiveCount":5}`)
+		expectRedrivePolicy("https://sqs/pdfs", `{"deadLetterTargetArn":"arn:aws:sqs:eu-west-1:123456789012:pdfs-dlq","maxReceiveCount":5}`)
+		mockSqs.EXPECT().GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("pdfs-dlq")}).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("https://sqs/pdfs-dlq")}, nil)
+		mockSqs.EXPECT().GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("emails-dlq"), QueueOwnerAWSAccountId: aws.String("210987654321")}).
+			Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("https://sqs/emails-dlq")}, nil)
+		expectMessages("https://sqs/pdfs-dlq", "0")
+		expectMessages("https://sqs/emails-dlq", "3")
+
+		_, err := probe.Check(ctx)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(probe.DeadLetterDepths()).To(Equal(map[string]int{"emails-dlq": 3, "pdfs-dlq": 0}))
+
+		expectMessages("https://sqs/emails", "1")
+		expectMessages("https://sqs/pdfs", "2")
+		expectMessages("https://sqs/pdfs-dlq", "0")
+		expectMessages("https://sqs/emails-dlq", "4")
+
+		_, err = probe.Check(ctx)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(probe.DeadLetterDepths()).To(Equal(map[string]int{"emails-dlq": 4, "pdfs-dlq": 0}))
+	})
+
+	It("Leaves out dead-letter queue which can't be read", func() {
+		config.DeadLetterQueues = []string{"emails-dlq"}
+		probe := newProbe()
+
+		expectMessages("https://sqs/emails", "1")
+		expectMessages("https://sqs/pdfs", "2")
+		mockSqs.EXPECT().GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("emails-dlq")}).Return(nil, errors.New("access denied"))
+
+		res, err := probe.Check(ctx)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(3))
+		Expect(probe.DeadLetterDepths()).To(BeEmpty())
+		Expect(probe.Failures()).To(BeEmpty())
+	})
+
+	DescribeTable("parseQueueARN()",
+		func(arn, name, owner string, valid bool) {
+			parsedName, parsedOwner, err := parseQueueARN(arn)
+
+			if !valid {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsedName).To(Equal(name))
+			Expect(parsedOwner).To(Equal(owner))
+		},
+		Entry("Queue ARN", "arn:aws:sqs:eu-west-1:123456789012:emails-dlq", "emails-dlq", "123456789012", true),
+		Entry("ARN of other service", "arn:aws:sns:eu-west-1:123456789012:emails", "", "", false),
+		Entry("Not an ARN", "emails-dlq", "", "", false),
+	)
+})
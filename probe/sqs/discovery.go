@@ -0,0 +1,179 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultResolveInterval = 5 * time.Minute
+	listQueuesPageSize     = 1000
+)
+
+// discovery finds queues by name prefix and tags, so queues created after autoscaler started are counted too
+type discovery struct {
+	prefix     string
+	tags       map[string]string
+	attributes []types.QueueAttributeName
+	interval   time.Duration
+
+	resolvedAt time.Time
+}
+
+func newDiscovery(config *Config) (*discovery, error) {
+	attributes, err := parseAttributes(config.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &discovery{
+		prefix:     config.QueueNamePrefix,
+		tags:       config.QueueTags,
+		attributes: attributes,
+		interval:   config.ResolveInterval,
+	}
+
+	if d.interval <= 0 {
+		d.interval = defaultResolveInterval
+	}
+
+	return d, nil
+}
+
+// refreshDiscoveredQueues resolves queues again once resolve interval passes, keeping previous set when it fails
+func (p *Probe) refreshDiscoveredQueues(ctx context.Context) {
+	if p.discovery == nil || time.Since(p.discovery.resolvedAt) < p.discovery.interval {
+		return
+	}
+
+	if err := p.discoverQueues(ctx); err != nil {
+		zap.S().With("error", err).Warn("failed to discover sqs queues, keeping previously discovered ones")
+	}
+}
+
+func (p *Probe) discoverQueues(ctx context.Context) error {
+	urls, err := p.listQueueURLs(ctx)
+	if err != nil {
+		return err
+	}
+
+	known := map[string]*queue{}
+	for _, q := range p.queues {
+		known[q.url] = q
+	}
+	for _, q := range p.discovered {
+		known[q.url] = q
+	}
+
+	var discovered []*queue
+
+	for _, url := range urls {
+		q, ok := known[url]
+		if ok && !q.discovered {
+			// listed explicitly in config
+			continue
+		}
+
+		if p.deadLetters != nil && p.isDeadLetterMonitored(queueNameFromURL(url), p.deadLetters.redriven) {
+			continue
+		}
+
+		matches, err := p.matchesTags(ctx, url)
+		if err != nil {
+			return err
+		}
+
+		if !matches {
+			continue
+		}
+
+		if !ok {
+			q = &queue{
+				name:       queueNameFromURL(url),
+				url:        url,
+				attributes: p.discovery.attributes,
+				weight:     1,
+				discovered: true,
+			}
+			zap.S().Infof("discovered sqs queue %v", q.name)
+		}
+
+		discovered = append(discovered, q)
+	}
+
+	for _, q := range p.discovered {
+		if !slices.Contains(discovered, q) {
+			zap.S().Infof("sqs queue %v no longer matches, not counting it", q.name)
+		}
+	}
+
+	p.discovered = discovered
+	p.discovery.resolvedAt = time.Now()
+
+	return nil
+}
+
+func (p *Probe) listQueueURLs(ctx context.Context) ([]string, error) {
+	input := &sqs.ListQueuesInput{MaxResults: aws.Int32(listQueuesPageSize)}
+	if p.discovery.prefix != "" {
+		input.QueueNamePrefix = aws.String(p.discovery.prefix)
+	}
+
+	var urls []string
+
+	for {
+		output, err := p.client.ListQueues(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		urls = append(urls, output.QueueUrls...)
+
+		if output.NextToken == nil {
+			return urls, nil
+		}
+
+		input.NextToken = output.NextToken
+	}
+}
+
+func (p *Probe) matchesTags(ctx context.Context, url string) (bool, error) {
+	if len(p.discovery.tags) == 0 {
+		return true, nil
+	}
+
+	output, err := p.client.ListQueueTags(ctx, &sqs.ListQueueTagsInput{QueueUrl: aws.String(url)})
+
+	var notExists *types.QueueDoesNotExist
+	if errors.As(err, &notExists) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	for key, value := range p.discovery.tags {
+		if output.Tags[key] != value {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (p *Probe) forgetDiscoveredQueue(q *queue) {
+	p.discovered = slices.DeleteFunc(p.discovered, func(other *queue) bool { return other == q })
+}
+
+func queueNameFromURL(url string) string {
+	return url[strings.LastIndex(url, "/")+1:]
+}
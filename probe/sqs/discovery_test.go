@@ -0,0 +1,200 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AirHelp/autoscaler/probe/sqs/mocks"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+)
+
+var _ = Describe("Discovery", func() {
+	var (
+		mockCtrl *gomock.Controller
+		mockSqs  *sqsMock.MockSqsClient
+		config   *Config
+
+		ctx = context.Background()
+	)
+
+	const (
+		partnerA   = "https://sqs/claims-partner-a"
+		partnerB   = "https://sqs/claims-partner-b"
+		partnerC   = "https://sqs/claims-partner-c"
+		partnerDLQ = "https://sqs/claims-partner-dlq"
+	)
+
+	queueNames := func(queues []*queue) []string {
+		var names []string
+		for _, q := range queues {
+			names = append(names, q.name)
+		}
+
+		return names
+	}
+
+	expectAttributes := func(url, messages string) *gomock.Call {
+		return mockSqs.EXPECT().GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+			QueueUrl:       aws.String(url),
+			AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages},
+		}).Return(&sqs.GetQueueAttributesOutput{Attributes: map[string]string{"ApproximateNumberOfMessages": messages}}, nil)
+	}
+
+	expectRedrivePolicy := func(url, policy string) {
+		attributes := map[string]string{}
+		if policy != "" {
+			attributes["RedrivePolicy"] = policy
+		}
+
+		mockSqs.EXPECT().GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+			QueueUrl:       aws.String(url),
+			AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameRedrivePolicy},
+		}).Return(&sqs.GetQueueAttributesOutput{Attributes: attributes}, nil)
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockSqs = sqsMock.NewMockSqsClient(mockCtrl)
+
+		config = &Config{
+			QueueNamePrefix: "claims-partner-",
+			Attributes:      []string{AttributeVisible},
+		}
+	})
+
+	It("Discovers queues by name prefix across pages", func() {
+		mockSqs.EXPECT().ListQueues(ctx, &sqs.ListQueuesInput{MaxResults: aws.Int32(1000), QueueNamePrefix: aws.String("claims-partner-")}).
+			Return(&sqs.ListQueuesOutput{QueueUrls: []string{partnerA}, NextToken: aws.String("page-2")}, nil)
+		mockSqs.EXPECT().ListQueues(ctx, &sqs.ListQueuesInput{MaxResults: aws.Int32(1000), QueueNamePrefix: aws.String("claims-partner-"), NextToken: aws.String("page-2")}).
+			Return(&sqs.ListQueuesOutput{QueueUrls: []string{partnerB}}, nil)
+
+		probe, err := New(ctx, config, &SQSService{Client: mockSqs}, 0)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(queueNames(probe.discovered)).To(Equal([]string{"claims-partner-a", "claims-partner-b"}))
+		Expect(probe.discovered[0].attributes).To(Equal([]types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages}))
+	})
+
+	It("Doesn't count explicitly listed queue twice", func() {
+		config.Queues = []QueueConfig{{Name: "claims-partner-a", Weight: 2}}
+
+		mockSqs.EXPECT().GetQueueUrl(ctx, gomock.Any()).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String(partnerA)}, nil)
+		mockSqs.EXPECT().ListQueues(ctx, gomock.Any()).Return(&sqs.ListQueuesOutput{QueueUrls: []string{partnerA, partnerB}}, nil)
+
+		probe, err := New(ctx, config, &SQSService{Client: mockSqs}, 0)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(queueNames(probe.queues)).To(Equal([]string{"claims-partner-a"}))
+		Expect(queueNames(probe.discovered)).To(Equal([]string{"claims-partner-b"}))
+	})
+
+	It("Discovers only queues having all given tags", func() {
+		config.QueueTags = map[string]string{"team": "claims", "autoscaled": "true"}
+
+		mockSqs.EXPECT().ListQueues(ctx, gomock.Any()).Return(&sqs.ListQueuesOutput{QueueUrls: []string{partnerA, partnerB, partnerC}}, nil)
+		mockSqs.EXPECT().ListQueueTags(ctx, &sqs.ListQueueTagsInput{QueueUrl: aws.String(partnerA)}).
+			Return(&sqs.ListQueueTagsOutput{Tags: map[string]string{"team": "claims", "autoscaled": "true", "partner": "a"}}, nil)
+		mockSqs.EXPECT().ListQueueTags(ctx, &sqs.ListQueueTagsInput{QueueUrl: aws.String(partnerB)}).
+			Return(&sqs.ListQueueTagsOutput{Tags: map[string]string{"team": "claims"}}, nil)
+		mockSqs.EXPECT().ListQueueTags(ctx, &sqs.ListQueueTagsInput{QueueUrl: aws.String(partnerC)}).
+			Return(nil, &types.QueueDoesNotExist{})
+
+		probe, err := New(ctx, config, &SQSService{Client: mockSqs}, 0)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(queueNames(probe.discovered)).To(Equal([]string{"claims-partner-a"}))
+	})
+
+	It("Doesn't discover dead-letter queues listed in config", func() {
+		config.DeadLetterQueues = []string{"claims-partner-dlq"}
+
+		mockSqs.EXPECT().ListQueues(ctx, gomock.Any()).Return(&sqs.ListQueuesOutput{QueueUrls: []string{partnerA, partnerDLQ}}, nil)
+
+		probe, err := New(ctx, config, &SQSService{Client: mockSqs}, 0)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(queueNames(probe.discovered)).To(Equal([]string{"claims-partner-a"}))
+	})
+
+	It("Stops counting discovered queue which is dead-letter queue of other one", func() {
+		config.DiscoverDeadLetterQueues = true
+
+		mockSqs.EXPECT().ListQueues(ctx, gomock.Any()).Return(&sqs.ListQueuesOutput{QueueUrls: []string{partnerA, partnerDLQ}}, nil)
+		probe, err := New(ctx, config, &SQSService{Client: mockSqs}, 0)
+		Expect(err).ToNot(HaveOccurred())
+
+		expectRedrivePolicy(partnerA, `{"deadLetterTargetArn":"arn:aws:sqs:eu-west-1:123456789012:claims-partner-dlq","maxReceiveCount":5}`)
+		expectRedrivePolicy(partnerDLQ, "")
+		expectAttributes(partnerA, "3")
+		mockSqs.EXPECT().GetQueueUrl(ctx, gomock.Any()).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String(partnerDLQ)}, nil)
+		expectAttributes(partnerDLQ, "40")
+
+		Expect(probe.Check(ctx)).To(Equal(3))
+		Expect(queueNames(probe.discovered)).To(Equal([]string{"claims-partner-a"}))
+		Expect(probe.Breakdown()).To(Equal(map[string]int{"claims-partner-a": 3}))
+		Expect(probe.DeadLetterDepths()).To(Equal(map[string]int{"claims-partner-dlq": 40}))
+	})
+
+	It("Returns error when initial discovery fails", func() {
+		mockSqs.EXPECT().ListQueues(ctx, gomock.Any()).Return(nil, errors.New("access denied"))
+
+		_, err := New(ctx, config, &SQSService{Client: mockSqs}, 0)
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("Check()", func() {
+		var probe *Probe
+
+		BeforeEach(func() {
+			mockSqs.EXPECT().ListQueues(ctx, gomock.Any()).Return(&sqs.ListQueuesOutput{QueueUrls: []string{partnerA, partnerB}}, nil)
+
+			var err error
+			probe, err = New(ctx, config, &SQSService{Client: mockSqs}, 0)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Counts discovered queues without resolving them before interval passes", func() {
+			expectAttributes(partnerA, "3")
+			expectAttributes(partnerB, "4")
+
+			Expect(probe.Check(ctx)).To(Equal(7))
+		})
+
+		It("Adds and removes queues when resolved again", func() {
+			probe.discovery.resolvedAt = time.Now().Add(-defaultResolveInterval)
+
+			mockSqs.EXPECT().ListQueues(ctx, gomock.Any()).Return(&sqs.ListQueuesOutput{QueueUrls: []string{partnerB, partnerC}}, nil)
+			expectAttributes(partnerB, "4")
+			expectAttributes(partnerC, "5")
+
+			Expect(probe.Check(ctx)).To(Equal(9))
+			Expect(queueNames(probe.discovered)).To(Equal([]string{"claims-partner-b", "claims-partner-c"}))
+		})
+
+		It("Keeps previously discovered queues when resolving fails", func() {
+			probe.discovery.resolvedAt = time.Now().Add(-defaultResolveInterval)
+
+			mockSqs.EXPECT().ListQueues(ctx, gomock.Any()).Return(nil, errors.New("throttled"))
+			expectAttributes(partnerA, "3")
+			expectAttributes(partnerB, "4")
+
+			Expect(probe.Check(ctx)).To(Equal(7))
+		})
+
+		It("Stops counting discovered queue which disappeared", func() {
+			expectAttributes(partnerA, "3")
+			mockSqs.EXPECT().GetQueueAttributes(ctx, gomock.Any()).Return(nil, &types.QueueDoesNotExist{})
+
+			Expect(probe.Check(ctx)).To(Equal(3))
+			Expect(queueNames(probe.discovered)).To(Equal([]string{"claims-partner-a"}))
+			Expect(probe.Breakdown()).To(Equal(map[string]int{"claims-partner-a": 3}))
+		})
+	})
+})
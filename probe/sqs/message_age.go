@@ -0,0 +1,122 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -destination=mocks/cloudWatchClientInterface.go -package sqsMock github.com/AirHelp/autoscaler/probe/sqs CloudWatchClient
+type CloudWatchClient interface {
+	GetMetricData(context.Context, *cloudwatch.GetMetricDataInput, ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error)
+}
+
+const (
+	messageAgeNamespace = "AWS/SQS"
+	messageAgeMetric    = "ApproximateAgeOfOldestMessage"
+	// SQS publishes metrics once a minute, window covers few delayed publications
+	messageAgeWindow = 10 * time.Minute
+	messageAgePeriod = 60
+	// GetMetricData accepts at most 500 queries in single request
+	metricQueriesPerRequest = 500
+)
+
+// messageAge reads age of the oldest message of queues from CloudWatch
+type messageAge struct {
+	client CloudWatchClient
+	max    time.Duration
+
+	// oldest message seen during the last check and its queue
+	oldest      time.Duration
+	oldestQueue string
+}
+
+// check finds the oldest message among given queues, queues without recent datapoints are skipped
+func (a *messageAge) check(ctx context.Context, queueNames []string) error {
+	a.oldest, a.oldestQueue = 0, ""
+
+	endTime := time.Now()
+	startTime := endTime.Add(-messageAgeWindow)
+
+	for start := 0; start < len(queueNames); start += metricQueriesPerRequest {
+		chunk := queueNames[start:min(start+metricQueriesPerRequest, len(queueNames))]
+
+		input := &cloudwatch.GetMetricDataInput{
+			StartTime:         aws.Time(startTime),
+			EndTime:           aws.Time(endTime),
+			ScanBy:            types.ScanByTimestampDescending,
+			MetricDataQueries: messageAgeQueries(chunk),
+		}
+
+		for {
+			output, err := a.client.GetMetricData(ctx, input)
+			if err != nil {
+				return err
+			}
+
+			for _, result := range output.MetricDataResults {
+				if len(result.Values) == 0 {
+					continue
+				}
+
+				index, err := strconv.Atoi(aws.ToString(result.Id)[1:])
+				if err != nil || index >= len(chunk) {
+					return fmt.Errorf("unexpected metric query id `%v`", aws.ToString(result.Id))
+				}
+
+				// values are sorted from the most recent one
+				age := time.Duration(result.Values[0]) * time.Second
+				if age > a.oldest {
+					a.oldest, a.oldestQueue = age, chunk[index]
+				}
+			}
+
+			if output.NextToken == nil {
+				break
+			}
+
+			input.NextToken = output.NextToken
+		}
+	}
+
+	zap.S().Debugf("oldest sqs message is %v old in queue %v", a.oldest, a.oldestQueue)
+
+	return nil
+}
+
+func messageAgeQueries(queueNames []string) []types.MetricDataQuery {
+	queries := make([]types.MetricDataQuery, 0, len(queueNames))
+
+	for i, name := range queueNames {
+		queries = append(queries, types.MetricDataQuery{
+			// id has to start with lowercase letter
+			Id: aws.String(fmt.Sprintf("q%d", i)),
+			MetricStat: &types.MetricStat{
+				Metric: &types.Metric{
+					Namespace:  aws.String(messageAgeNamespace),
+					MetricName: aws.String(messageAgeMetric),
+					Dimensions: []types.Dimension{{Name: aws.String("QueueName"), Value: aws.String(name)}},
+				},
+				Period: aws.Int32(messageAgePeriod),
+				Stat:   aws.String(string(types.StatisticMaximum)),
+			},
+		})
+	}
+
+	return queries
+}
+
+// breach describes the oldest message when it waits longer than allowed
+func (a *messageAge) breach() string {
+	if a.oldest <= a.max {
+		return ""
+	}
+
+	return fmt.Sprintf("oldest message of queue `%v` is %v old, over %v", a.oldestQueue, a.oldest, a.max)
+}
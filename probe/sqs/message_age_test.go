@@ -0,0 +1,123 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AirHelp/autoscaler/probe/sqs/mocks"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+)
+
+var _ = Describe("Message age", func() {
+	var (
+		mockCtrl       *gomock.Controller
+		mockSqs        *sqsMock.MockSqsClient
+		mockCloudWatch *sqsMock.MockCloudWatchClient
+		probe          *Probe
+
+		ctx = context.Background()
+	)
+
+	expectMessages := func(url, messages string) {
+		mockSqs.EXPECT().GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+			QueueUrl:       aws.String(url),
+			AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages},
+		}).Return(&sqs.GetQueueAttributesOutput{Attributes: map[string]string{"ApproximateNumberOfMessages": messages}}, nil)
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockSqs = sqsMock.NewMockSqsClient(mockCtrl)
+		mockCloudWatch = sqsMock.NewMockCloudWatchClient(mockCtrl)
+
+		mockSqs.EXPECT().GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("emails")}).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("https://sqs/emails")}, nil)
+		mockSqs.EXPECT().GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("pdfs")}).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("https://sqs/pdfs")}, nil)
+
+		var err error
+		probe, err = New(ctx, &Config{
+			Queues:        []QueueConfig{{Name: "emails"}, {Name: "pdfs"}},
+			Attributes:    []string{AttributeVisible},
+			MaxMessageAge: 15 * time.Minute,
+		}, &SQSService{Client: mockSqs, CloudWatch: mockCloudWatch}, 20)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Requires cloudwatch client", func() {
+		_, err := New(ctx, &Config{Queues: []QueueConfig{{Name: "emails"}}, MaxMessageAge: time.Minute}, &SQSService{Client: mockSqs}, 20)
+
+		Expect(err).To(Equal(ErrNoCloudWatch))
+	})
+
+	It("Queries the oldest message age of every queue", func() {
+		expectMessages("https://sqs/emails", "1")
+		expectMessages("https://sqs/pdfs", "2")
+		mockCloudWatch.EXPECT().GetMetricData(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, input *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+			Expect(input.EndTime.Sub(*input.StartTime)).To(Equal(messageAgeWindow))
+			Expect(input.ScanBy).To(Equal(cwTypes.ScanByTimestampDescending))
+			Expect(input.MetricDataQueries).To(HaveLen(2))
+
+			query := input.MetricDataQueries[1]
+			Expect(*query.Id).To(Equal("q1"))
+			Expect(*query.MetricStat.Metric.Namespace).To(Equal("AWS/SQS"))
+			Expect(*query.MetricStat.Metric.MetricName).To(Equal("ApproximateAgeOfOldestMessage"))
+			Expect(*query.MetricStat.Metric.Dimensions[0].Value).To(Equal("pdfs"))
+			Expect(*query.MetricStat.Stat).To(Equal("Maximum"))
+
+			return &cloudwatch.GetMetricDataOutput{MetricDataResults: []cwTypes.MetricDataResult{
+				{Id: aws.String("q0"), Values: []float64{120, 1500}},
+				{Id: aws.String("q1"), Values: []float64{30}},
+			}}, nil
+		})
+
+		res, err := probe.Check(ctx)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(3))
+		Expect(probe.age.oldest).To(Equal(2 * time.Minute))
+		Expect(probe.LatencyBreach()).To(BeEmpty())
+	})
+
+	It("Reports breach when message is older than max message age", func() {
+		expectMessages("https://sqs/emails", "1")
+		expectMessages("https://sqs/pdfs", "0")
+		mockCloudWatch.EXPECT().GetMetricData(ctx, gomock.Any()).Return(&cloudwatch.GetMetricDataOutput{
+			MetricDataResults: []cwTypes.MetricDataResult{{Id: aws.String("q0"), Values: []float64{60}}},
+			NextToken:         aws.String("page-2"),
+		}, nil)
+		mockCloudWatch.EXPECT().GetMetricData(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, input *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+			Expect(*input.NextToken).To(Equal("page-2"))
+
+			return &cloudwatch.GetMetricDataOutput{MetricDataResults: []cwTypes.MetricDataResult{
+				{Id: aws.String("q1"), Values: []float64{1200}},
+			}}, nil
+		})
+
+		res, err := probe.Check(ctx)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(1))
+		Expect(probe.LatencyBreach()).To(Equal("oldest message of queue `pdfs` is 20m0s old, over 15m0s"))
+	})
+
+	It("Scales on messages count only when message age can't be read", func() {
+		probe.age.oldest, probe.age.oldestQueue = time.Hour, "emails"
+
+		expectMessages("https://sqs/emails", "1")
+		expectMessages("https://sqs/pdfs", "2")
+		mockCloudWatch.EXPECT().GetMetricData(ctx, gomock.Any()).Return(nil, errors.New("throttled"))
+
+		res, err := probe.Check(ctx)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(3))
+		Expect(probe.LatencyBreach()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,62 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/AirHelp/autoscaler/probe/sqs (interfaces: CloudWatchClient)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mocks/cloudWatchClientInterface.go -package sqsMock github.com/AirHelp/autoscaler/probe/sqs CloudWatchClient
+//
+
+// Package sqsMock is a generated GoMock package.
+package sqsMock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	cloudwatch "github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCloudWatchClient is a mock of CloudWatchClient interface.
+type MockCloudWatchClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockCloudWatchClientMockRecorder
+	isgomock struct{}
+}
+
+// MockCloudWatchClientMockRecorder is the mock recorder for MockCloudWatchClient.
+type MockCloudWatchClientMockRecorder struct {
+	mock *MockCloudWatchClient
+}
+
+// NewMockCloudWatchClient creates a new mock instance.
+func NewMockCloudWatchClient(ctrl *gomock.Controller) *MockCloudWatchClient {
+	mock := &MockCloudWatchClient{ctrl: ctrl}
+	mock.recorder = &MockCloudWatchClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCloudWatchClient) EXPECT() *MockCloudWatchClientMockRecorder {
+	return m.recorder
+}
+
+// GetMetricData mocks base method.
+func (m *MockCloudWatchClient) GetMetricData(arg0 context.Context, arg1 *cloudwatch.GetMetricDataInput, arg2 ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetMetricData", varargs...)
+	ret0, _ := ret[0].(*cloudwatch.GetMetricDataOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMetricData indicates an expected call of GetMetricData.
+func (mr *MockCloudWatchClientMockRecorder) GetMetricData(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetricData", reflect.TypeOf((*MockCloudWatchClient)(nil).GetMetricData), varargs...)
+}
@@ -3,93 +3,473 @@ package sqs
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"slices"
 	"strconv"
+	"sync"
+	"time"
 
-	awsCfg "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.uber.org/zap"
 )
 
 //go:generate mockgen -destination=mocks/sqsClientInterface.go -package sqsMock github.com/AirHelp/autoscaler/probe/sqs SqsClient
 type SQSClient interface {
 	GetQueueUrl(context.Context, *sqs.GetQueueUrlInput, ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error)
 	GetQueueAttributes(context.Context, *sqs.GetQueueAttributesInput, ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error)
+	ListQueues(context.Context, *sqs.ListQueuesInput, ...func(*sqs.Options)) (*sqs.ListQueuesOutput, error)
+	ListQueueTags(context.Context, *sqs.ListQueueTagsInput, ...func(*sqs.Options)) (*sqs.ListQueueTagsOutput, error)
 }
 
+// Message counts which can be taken into account
+const (
+	AttributeVisible  = "visible"
+	AttributeInFlight = "in_flight"
+	AttributeDelayed  = "delayed"
+)
+
+var attributeNames = map[string]types.QueueAttributeName{
+	AttributeVisible:  types.QueueAttributeNameApproximateNumberOfMessages,
+	AttributeInFlight: types.QueueAttributeNameApproximateNumberOfMessagesNotVisible,
+	AttributeDelayed:  types.QueueAttributeNameApproximateNumberOfMessagesDelayed,
+}
+
+var defaultAttributes = []string{AttributeVisible, AttributeInFlight}
+
+// defaultConcurrency is number of queues read at the same time
+const defaultConcurrency = 5
+
 type Config struct {
-	Queues []string `yaml:"queues"`
+	Queues []QueueConfig `yaml:"queues"`
+
+	// Attributes are counted for queues which don't specify their own
+	Attributes []string `yaml:"attributes"`
+
+	// Queues matching both name prefix and tags are discovered and counted along with listed queues
+	QueueNamePrefix string            `yaml:"queue_name_prefix"`
+	QueueTags       map[string]string `yaml:"queue_tags"`
+	ResolveInterval time.Duration     `yaml:"resolve_interval"`
+
+	// Concurrency limits number of queues read at the same time
+	Concurrency int `yaml:"concurrency"`
+
+	// Dead-letter queues are monitored and reported, their messages never count toward scaling
+	DeadLetterQueues         []string `yaml:"dead_letter_queues"`
+	DiscoverDeadLetterQueues bool     `yaml:"discover_dead_letter_queues"`
+
+	// Deployment is scaled up when the oldest message of any queue is older, regardless of messages count
+	MaxMessageAge time.Duration `yaml:"max_message_age"`
+
+	// AWS client settings, process wide configuration is used when not set
+	Region      string `yaml:"region"`
+	EndpointURL string `yaml:"endpoint_url"`
+	RoleARN     string `yaml:"role_arn"`
+	ExternalID  string `yaml:"external_id"`
+	SessionName string `yaml:"session_name"`
+
+	CloudWatchEndpointURL string `yaml:"cloudwatch_endpoint_url"`
+}
+
+// QueueConfig can be given as a plain queue name or as a hash with options
+type QueueConfig struct {
+	Name       string   `yaml:"name"`
+	Attributes []string `yaml:"attributes"`
+	Weight     float64  `yaml:"weight"`
+	Threshold  int      `yaml:"threshold"`
+}
+
+func (qc *QueueConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&qc.Name); err == nil {
+		return nil
+	}
+
+	type plain QueueConfig
+	return unmarshal((*plain)(qc))
+}
+
+type queue struct {
+	name       string
+	url        string
+	attributes []types.QueueAttributeName
+	weight     float64
+	threshold  int
+	discovered bool
+
+	// owner is AWS account of queue, set when it differs from account of credentials
+	owner string
 }
 
 type Probe struct {
-	queueURLs []string
-	client    SQSClient
+	queues     []*queue
+	discovered []*queue
+	discovery  *discovery
+	client     SQSClient
+
+	concurrency int
+	age         *messageAge
+	deadLetters *deadLetters
+
+	// threshold of deployment, used to turn per queue thresholds into messages count
+	threshold int
+	breakdown map[string]int
+	failures  map[string]string
 }
 
 type SQSService struct {
-	Client SQSClient
+	Client     SQSClient
+	CloudWatch CloudWatchClient
 }
 
-var ErrNoQueueSpecified = errors.New("no queues provided")
+var (
+	ErrNoQueueSpecified = errors.New("no queues provided")
+	ErrNoQueueName      = errors.New("queue name cannot be empty")
+	ErrConcurrency      = errors.New("concurrency cannot be negative")
+	ErrMaxMessageAge    = errors.New("max message age cannot be negative")
+	ErrNoCloudWatch     = errors.New("cloudwatch client is required to check message age")
+)
 
 func NewSQSService(ctx context.Context) (*SQSService, error) {
-	cfg, err := awsCfg.LoadDefaultConfig(ctx)
-	if err != nil {
-		return &SQSService{}, err
-	}
-
-	return &SQSService{
-		Client: sqs.NewFromConfig(cfg),
-	}, nil
+	return newSQSService(ctx, clientSettings{})
 }
 
-func New(ctx context.Context, config *Config, s *SQSService) (*Probe, error) {
-	var queueURLs []string
-	if len(config.Queues) == 0 {
+// New resolves URLs of configured queues, queues which don't exist yet are resolved again on check.
+// Threshold of deployment is needed only when queues have their own thresholds.
+func New(ctx context.Context, config *Config, s *SQSService, threshold int) (*Probe, error) {
+	if len(config.Queues) == 0 && config.QueueNamePrefix == "" && len(config.QueueTags) == 0 {
 		return &Probe{}, ErrNoQueueSpecified
 	}
-	queueURLInput := &sqs.GetQueueUrlInput{}
-	for _, queue := range config.Queues {
-		queueURLInput.QueueName = &queue
-		res, err := s.Client.GetQueueUrl(ctx, queueURLInput)
+
+	if config.Concurrency < 0 {
+		return &Probe{}, ErrConcurrency
+	}
+
+	if config.MaxMessageAge < 0 {
+		return &Probe{}, ErrMaxMessageAge
+	}
+
+	var queues []*queue
+
+	for _, qc := range config.Queues {
+		q, err := newQueue(qc, config.Attributes)
+		if err != nil {
+			return &Probe{}, err
+		}
+
+		queues = append(queues, q)
+	}
+
+	p := &Probe{
+		queues:      queues,
+		client:      s.Client,
+		concurrency: config.Concurrency,
+		threshold:   threshold,
+	}
+
+	if config.MaxMessageAge > 0 {
+		if s.CloudWatch == nil {
+			return &Probe{}, ErrNoCloudWatch
+		}
+
+		p.age = &messageAge{client: s.CloudWatch, max: config.MaxMessageAge}
+	}
+
+	if len(config.DeadLetterQueues) > 0 || config.DiscoverDeadLetterQueues {
+		dl, err := newDeadLetters(config)
+		if err != nil {
+			return &Probe{}, err
+		}
+
+		p.deadLetters = dl
+	}
+
+	for _, q := range queues {
+		err := p.resolveURL(ctx, q)
+
+		var notExists *types.QueueDoesNotExist
+		if errors.As(err, &notExists) {
+			zap.S().Warnf("sqs queue %v doesn't exist, resolving its URL again on next check", q.name)
+		} else if err != nil {
+			return &Probe{}, err
+		}
+	}
+
+	if config.QueueNamePrefix != "" || len(config.QueueTags) > 0 {
+		d, err := newDiscovery(config)
 		if err != nil {
 			return &Probe{}, err
 		}
+		p.discovery = d
 
-		queueURLs = append(queueURLs, *res.QueueUrl)
+		if err := p.discoverQueues(ctx); err != nil {
+			return &Probe{}, err
+		}
 	}
-	return &Probe{
-		queueURLs: queueURLs,
-		client:    s.Client,
-	}, nil
+
+	return p, nil
+}
+
+func newQueue(qc QueueConfig, defaults []string) (*queue, error) {
+	if qc.Name == "" {
+		return nil, ErrNoQueueName
+	}
+
+	q := &queue{
+		name:      qc.Name,
+		weight:    qc.Weight,
+		threshold: qc.Threshold,
+	}
+
+	if q.weight == 0 {
+		q.weight = 1
+	}
+
+	if q.weight < 0 {
+		return nil, fmt.Errorf("weight of queue `%v` cannot be negative", qc.Name)
+	}
+
+	if q.threshold < 0 {
+		return nil, fmt.Errorf("threshold of queue `%v` cannot be negative", qc.Name)
+	}
+
+	attributes := qc.Attributes
+	if len(attributes) == 0 {
+		attributes = defaults
+	}
+
+	var err error
+	q.attributes, err = parseAttributes(attributes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config of queue `%v`: %w", qc.Name, err)
+	}
+
+	return q, nil
+}
+
+func parseAttributes(attributes []string) ([]types.QueueAttributeName, error) {
+	if len(attributes) == 0 {
+		attributes = defaultAttributes
+	}
+
+	var names []types.QueueAttributeName
+
+	for _, attribute := range attributes {
+		name, ok := attributeNames[attribute]
+		if !ok {
+			return nil, fmt.Errorf("unknown attribute `%v`, expected one of %v, %v, %v", attribute, AttributeVisible, AttributeInFlight, AttributeDelayed)
+		}
+
+		names = append(names, name)
+	}
+
+	return names, nil
 }
 
 func (p *Probe) Kind() string {
 	return "sqs"
 }
 
+// Check sums weighted message counts of all queues. Count of queue with its own threshold is scaled,
+// so that threshold of deployment is reached when queue reaches its threshold.
+// Queues are read concurrently, queues which fail are left out of result and reported by Failures.
+// Error is returned only when no queue could be read.
 func (p *Probe) Check(ctx context.Context) (int, error) {
-	var acc int
+	p.refreshDiscoveredQueues(ctx)
+
+	if p.deadLetters != nil {
+		p.refreshRedrivenQueues(ctx)
+	}
 
-	for _, queueURL := range p.queueURLs {
-		output, err := p.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
-			QueueUrl:       &queueURL,
-			AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages, types.QueueAttributeNameApproximateNumberOfMessagesNotVisible},
+	queues := p.countedQueues()
+	results := p.readQueues(ctx, queues)
+
+	var (
+		acc       float64
+		errs      []error
+		read      []string
+		breakdown = make(map[string]int, len(queues))
+		failures  = map[string]string{}
+	)
+
+	for i, q := range queues {
+		result := results[i]
+
+		switch {
+		case result.gone:
+			zap.S().Warnf("discovered sqs queue %v no longer exists, not counting it", q.name)
+			p.forgetDiscoveredQueue(q)
+		case result.err != nil:
+			zap.S().With("error", result.err).Warnf("failed to read sqs queue %v, not counting it", q.name)
+			failures[q.name] = result.err.Error()
+			errs = append(errs, result.err)
+		default:
+			breakdown[q.name] = int(math.Ceil(result.value))
+			acc += result.value
+			read = append(read, q.name)
+		}
+	}
+
+	p.breakdown = breakdown
+	p.failures = failures
+
+	if len(errs) > 0 && len(breakdown) == 0 {
+		return 0, errors.Join(errs...)
+	}
+
+	if p.deadLetters != nil {
+		p.checkDeadLetters(ctx)
+	}
+
+	if p.age != nil {
+		if err := p.age.check(ctx, read); err != nil {
+			zap.S().With("error", err).Warn("failed to read age of the oldest sqs message, scaling on messages count only")
+		}
+	}
+
+	return int(math.Ceil(acc)), nil
+}
+
+// countedQueues returns queues which messages count toward scaling
+func (p *Probe) countedQueues() []*queue {
+	return append(slices.Clip(p.queues), p.discovered...)
+}
+
+// readQueues reads queues concurrently, results are in order of given queues
+func (p *Probe) readQueues(ctx context.Context, queues []*queue) []queueResult {
+	results := make([]queueResult, len(queues))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, p.parallelism())
+
+	for i, q := range queues {
+		wg.Go(func() {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			results[i] = p.checkQueue(ctx, q)
 		})
+	}
+
+	wg.Wait()
+
+	return results
+}
 
+// queueResult is outcome of reading single queue
+type queueResult struct {
+	value float64
+	err   error
+	// gone is set when discovered queue no longer exists
+	gone bool
+}
+
+func (p *Probe) checkQueue(ctx context.Context, q *queue) queueResult {
+	output, err := p.queueAttributes(ctx, q)
+
+	var notExists *types.QueueDoesNotExist
+	if q.discovered && errors.As(err, &notExists) {
+		return queueResult{gone: true}
+	}
+
+	if err != nil {
+		return queueResult{err: fmt.Errorf("failed to read queue `%v`: %w", q.name, err)}
+	}
+
+	var messages int
+
+	for _, attribute := range q.attributes {
+		size, err := strconv.Atoi(output.Attributes[string(attribute)])
 		if err != nil {
-			return 0, err
+			return queueResult{err: fmt.Errorf("invalid %v of queue `%v`: %w", attribute, q.name, err)}
 		}
 
-		for _, num := range output.Attributes {
-			size, err := strconv.Atoi(num)
+		messages += size
+	}
+
+	value := float64(messages) * q.weight
+	if q.threshold > 0 && p.threshold > 0 {
+		value = value * float64(p.threshold) / float64(q.threshold)
+	}
 
-			if err != nil {
-				return 0, err
-			}
+	zap.S().Debugf("sqs queue %v: %d messages, weighted %.2f", q.name, messages, value)
+
+	return queueResult{value: value}
+}
 
-			acc += size
+// queueAttributes resolves URL of listed queue when it wasn't found before or when queue was recreated
+func (p *Probe) queueAttributes(ctx context.Context, q *queue) (*sqs.GetQueueAttributesOutput, error) {
+	if q.url == "" {
+		if err := p.resolveURL(ctx, q); err != nil {
+			return nil, err
 		}
 	}
 
-	return acc, nil
+	output, err := p.getQueueAttributes(ctx, q)
+
+	var notExists *types.QueueDoesNotExist
+	if q.discovered || !errors.As(err, &notExists) {
+		return output, err
+	}
+
+	zap.S().Infof("sqs queue %v not found at %v, resolving its URL again", q.name, q.url)
+
+	if err := p.resolveURL(ctx, q); err != nil {
+		return nil, err
+	}
+
+	return p.getQueueAttributes(ctx, q)
+}
+
+func (p *Probe) getQueueAttributes(ctx context.Context, q *queue) (*sqs.GetQueueAttributesOutput, error) {
+	return p.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(q.url),
+		AttributeNames: q.attributes,
+	})
+}
+
+// resolveURL looks up URL of queue by its name, URL is cleared when lookup fails
+func (p *Probe) resolveURL(ctx context.Context, q *queue) error {
+	input := &sqs.GetQueueUrlInput{QueueName: aws.String(q.name)}
+	if q.owner != "" {
+		input.QueueOwnerAWSAccountId = aws.String(q.owner)
+	}
+
+	output, err := p.client.GetQueueUrl(ctx, input)
+	if err != nil {
+		q.url = ""
+		return err
+	}
+
+	q.url = *output.QueueUrl
+
+	return nil
+}
+
+func (p *Probe) parallelism() int {
+	if p.concurrency <= 0 {
+		return defaultConcurrency
+	}
+
+	return p.concurrency
+}
+
+// Breakdown returns contribution of each queue to the last check result
+func (p *Probe) Breakdown() map[string]int {
+	return p.breakdown
+}
+
+// LatencyBreach describes the oldest message when it waited longer than max message age during the last check
+func (p *Probe) LatencyBreach() string {
+	if p.age == nil {
+		return ""
+	}
+
+	return p.age.breach()
+}
+
+// Failures returns errors of queues which couldn't be read during the last check
+func (p *Probe) Failures() map[string]string {
+	return p.failures
 }
@@ -3,27 +3,129 @@ package sqs
 import (
 	"context"
 	"errors"
+	"sync/atomic"
+	"time"
 
 	"github.com/AirHelp/autoscaler/probe/sqs/mocks"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.uber.org/mock/gomock"
+	"gopkg.in/yaml.v2"
 )
 
 var _ = Describe("Probe", func() {
 	Describe("New()", func() {
 		var config Config
 		var ctx = context.Background()
-		var SQSService *SQSService
+		var sqsService *SQSService
 
 		It("Returns error when no queue provided", func() {
-			probe, err := New(ctx, &config, SQSService)
+			probe, err := New(ctx, &config, sqsService, 0)
 
 			Expect(probe).To(Equal(&Probe{}))
 			Expect(err).To(Equal(ErrNoQueueSpecified))
 		})
+
+		DescribeTable("Returns error on invalid queue config",
+			func(qc QueueConfig) {
+				_, err := New(ctx, &Config{Queues: []QueueConfig{qc}}, sqsService, 0)
+				Expect(err).To(HaveOccurred())
+			},
+			Entry("When queue has no name", QueueConfig{}),
+			Entry("When attribute is unknown", QueueConfig{Name: "q1", Attributes: []string{"dead"}}),
+			Entry("When weight is negative", QueueConfig{Name: "q1", Weight: -1}),
+			Entry("When threshold is negative", QueueConfig{Name: "q1", Threshold: -1}),
+		)
+
+		It("Resolves queue URLs and applies defaults", func() {
+			mockCtrl := gomock.NewController(GinkgoT())
+			mockSqs := sqsMock.NewMockSqsClient(mockCtrl)
+
+			mockSqs.EXPECT().GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("emails")}).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("https://sqs/emails")}, nil)
+			mockSqs.EXPECT().GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("pdfs")}).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("https://sqs/pdfs")}, nil)
+
+			probe, err := New(ctx, &Config{
+				Queues: []QueueConfig{
+					{Name: "emails"},
+					{Name: "pdfs", Attributes: []string{AttributeVisible, AttributeDelayed}, Weight: 10, Threshold: 5},
+				},
+			}, &SQSService{Client: mockSqs}, 20)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(probe.threshold).To(Equal(20))
+			Expect(probe.queues).To(Equal([]*queue{
+				{
+					name:       "emails",
+					url:        "https://sqs/emails",
+					attributes: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages, types.QueueAttributeNameApproximateNumberOfMessagesNotVisible},
+					weight:     1,
+				},
+				{
+					name:       "pdfs",
+					url:        "https://sqs/pdfs",
+					attributes: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages, types.QueueAttributeNameApproximateNumberOfMessagesDelayed},
+					weight:     10,
+					threshold:  5,
+				},
+			}))
+		})
+
+		It("Resolves later URL of queue which doesn't exist yet", func() {
+			mockCtrl := gomock.NewController(GinkgoT())
+			mockSqs := sqsMock.NewMockSqsClient(mockCtrl)
+
+			mockSqs.EXPECT().GetQueueUrl(ctx, gomock.Any()).Return(nil, &types.QueueDoesNotExist{})
+
+			probe, err := New(ctx, &Config{Queues: []QueueConfig{{Name: "emails"}}}, &SQSService{Client: mockSqs}, 0)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(probe.queues[0].url).To(BeEmpty())
+		})
+
+		It("Returns error when queue URL lookup fails", func() {
+			mockCtrl := gomock.NewController(GinkgoT())
+			mockSqs := sqsMock.NewMockSqsClient(mockCtrl)
+
+			mockSqs.EXPECT().GetQueueUrl(ctx, gomock.Any()).Return(nil, errors.New("access denied"))
+
+			_, err := New(ctx, &Config{Queues: []QueueConfig{{Name: "emails"}}}, &SQSService{Client: mockSqs}, 0)
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Returns error when concurrency is negative", func() {
+			_, err := New(ctx, &Config{Queues: []QueueConfig{{Name: "emails"}}, Concurrency: -1}, sqsService, 0)
+
+			Expect(err).To(Equal(ErrConcurrency))
+		})
+	})
+
+	Describe("Config", func() {
+		It("Accepts queues given as names and as hashes", func() {
+			var config Config
+
+			err := yaml.Unmarshal([]byte(`
+attributes: [visible]
+queues:
+  - emails
+  - name: pdfs
+    attributes: [visible, in_flight, delayed]
+    weight: 10
+    threshold: 5
+`), &config)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config).To(Equal(Config{
+				Attributes: []string{AttributeVisible},
+				Queues: []QueueConfig{
+					{Name: "emails"},
+					{Name: "pdfs", Attributes: []string{AttributeVisible, AttributeInFlight, AttributeDelayed}, Weight: 10, Threshold: 5},
+				},
+			}))
+		})
 	})
 
 	Describe("Probe receiver", func() {
@@ -41,9 +143,19 @@ var _ = Describe("Probe", func() {
 			mockCtrl = gomock.NewController(GinkgoT())
 			mockSqs = sqsMock.NewMockSqsClient(mockCtrl)
 
+			var queues []*queue
+			for _, queueURL := range queueURLs {
+				queues = append(queues, &queue{
+					name:       queueURL,
+					url:        queueURL,
+					attributes: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages, types.QueueAttributeNameApproximateNumberOfMessagesNotVisible},
+					weight:     1,
+				})
+			}
+
 			probe = Probe{
-				queueURLs: queueURLs,
-				client:    mockSqs,
+				queues: queues,
+				client: mockSqs,
 			}
 		})
 
@@ -51,6 +163,14 @@ var _ = Describe("Probe", func() {
 			mockCtrl.Finish()
 		})
 
+		// expectMessages expects queue read with both visible and in flight messages equal to given count
+		expectMessages := func(url, messages string) {
+			mockSqs.EXPECT().GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+				QueueUrl:       aws.String(url),
+				AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages, types.QueueAttributeNameApproximateNumberOfMessagesNotVisible},
+			}).Return(&sqs.GetQueueAttributesOutput{Attributes: map[string]string{"ApproximateNumberOfMessages": messages, "ApproximateNumberOfMessagesNotVisible": messages}}, nil)
+		}
+
 		Describe("Kind()", func() {
 			It("Return sqs string", func() {
 				Expect(probe.Kind()).To(Equal("sqs"))
@@ -99,18 +219,165 @@ var _ = Describe("Probe", func() {
 
 				Expect(res).To(Equal(1012))
 				Expect(err).ToNot(HaveOccurred())
+				Expect(probe.Breakdown()).To(Equal(map[string]int{"q1": 11, "q2": 0, "q3": 1001}))
+			})
+
+			It("Counts selected attributes with queue weight and threshold", func() {
+				probe.threshold = 20
+				probe.queues = []*queue{
+					{
+						name:       "emails",
+						url:        queueURLs[0],
+						attributes: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages},
+						weight:     1,
+					},
+					{
+						name:       "pdfs",
+						url:        queueURLs[1],
+						attributes: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages, types.QueueAttributeNameApproximateNumberOfMessagesDelayed},
+						weight:     10,
+					},
+					{
+						name:       "reports",
+						url:        queueURLs[2],
+						attributes: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages},
+						weight:     1,
+						threshold:  5,
+					},
+				}
+
+				mockSqs.EXPECT().GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+					QueueUrl:       &queueURLs[0],
+					AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages},
+				}).Return(&sqs.GetQueueAttributesOutput{Attributes: map[string]string{"ApproximateNumberOfMessages": "7"}}, nil)
+				mockSqs.EXPECT().GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+					QueueUrl:       &queueURLs[1],
+					AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages, types.QueueAttributeNameApproximateNumberOfMessagesDelayed},
+				}).Return(&sqs.GetQueueAttributesOutput{Attributes: map[string]string{"ApproximateNumberOfMessages": "2", "ApproximateNumberOfMessagesDelayed": "1"}}, nil)
+				mockSqs.EXPECT().GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+					QueueUrl:       &queueURLs[2],
+					AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages},
+				}).Return(&sqs.GetQueueAttributesOutput{Attributes: map[string]string{"ApproximateNumberOfMessages": "3"}}, nil)
+
+				res, err := probe.Check(ctx)
+
+				Expect(err).ToNot(HaveOccurred())
+				// 7 emails, 3 pdfs weighted 10, 3 reports with own threshold 4 times lower than deployment threshold
+				Expect(res).To(Equal(7 + 30 + 12))
+				Expect(probe.Breakdown()).To(Equal(map[string]int{"emails": 7, "pdfs": 30, "reports": 12}))
 			})
 
-			It("When error happens it proxies error", func() {
+			It("Leaves out queue with missing attribute and reports it", func() {
+				mockSqs.EXPECT().GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+					QueueUrl:       &queueURLs[0],
+					AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages, types.QueueAttributeNameApproximateNumberOfMessagesNotVisible},
+				}).Return(&sqs.GetQueueAttributesOutput{Attributes: map[string]string{"ApproximateNumberOfMessages": "1"}}, nil)
+				expectMessages(queueURLs[1], "2")
+				expectMessages(queueURLs[2], "3")
+
+				res, err := probe.Check(ctx)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(10))
+				Expect(probe.Breakdown()).To(Equal(map[string]int{"q2": 4, "q3": 6}))
+				Expect(probe.Failures()).To(HaveKey("q1"))
+			})
+
+			It("Keeps partial result when some queues fail", func() {
 				mockSqs.EXPECT().GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
 					QueueUrl:       &queueURLs[0],
 					AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages, types.QueueAttributeNameApproximateNumberOfMessagesNotVisible},
 				}).Return(&sqs.GetQueueAttributesOutput{}, errors.New("access denied"))
+				expectMessages(queueURLs[1], "2")
+				expectMessages(queueURLs[2], "3")
+
+				res, err := probe.Check(ctx)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(10))
+				Expect(probe.Failures()).To(Equal(map[string]string{"q1": "failed to read queue `q1`: access denied"}))
+			})
+
+			It("When all queues fail it proxies errors", func() {
+				mockSqs.EXPECT().GetQueueAttributes(ctx, gomock.Any()).Return(&sqs.GetQueueAttributesOutput{}, errors.New("access denied")).Times(3)
 
 				res, err := probe.Check(ctx)
 
 				Expect(res).To(Equal(0))
-				Expect(err).To(HaveOccurred())
+				Expect(err).To(MatchError(ContainSubstring("access denied")))
+				Expect(probe.Failures()).To(HaveLen(3))
+			})
+
+			It("Resolves URL of queue which didn't exist at start", func() {
+				probe.queues = probe.queues[:1]
+				probe.queues[0].url = ""
+
+				mockSqs.EXPECT().GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("q1")}).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("https://sqs/q1")}, nil)
+				expectMessages("https://sqs/q1", "4")
+
+				res, err := probe.Check(ctx)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(8))
+				Expect(probe.queues[0].url).To(Equal("https://sqs/q1"))
+			})
+
+			It("Resolves URL again when queue was recreated", func() {
+				probe.queues = probe.queues[:1]
+
+				mockSqs.EXPECT().GetQueueAttributes(ctx, gomock.Any()).Return(nil, &types.QueueDoesNotExist{})
+				mockSqs.EXPECT().GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("q1")}).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("https://sqs/q1-recreated")}, nil)
+				expectMessages("https://sqs/q1-recreated", "1")
+
+				res, err := probe.Check(ctx)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(2))
+			})
+
+			It("Reports queue which still doesn't exist and resolves it on next check", func() {
+				probe.queues[0].url = ""
+
+				mockSqs.EXPECT().GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("q1")}).Return(nil, &types.QueueDoesNotExist{}).Times(2)
+				expectMessages(queueURLs[1], "1")
+				expectMessages(queueURLs[2], "1")
+
+				res, err := probe.Check(ctx)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(4))
+				Expect(probe.Failures()).To(HaveKey("q1"))
+
+				mockSqs.EXPECT().GetQueueAttributes(ctx, gomock.Any()).Return(&sqs.GetQueueAttributesOutput{Attributes: map[string]string{"ApproximateNumberOfMessages": "0", "ApproximateNumberOfMessagesNotVisible": "0"}}, nil).Times(2)
+
+				_, err = probe.Check(ctx)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("Reads no more queues at the same time than concurrency allows", func() {
+				probe.concurrency = 2
+
+				var running, peak atomic.Int32
+				mockSqs.EXPECT().GetQueueAttributes(ctx, gomock.Any()).DoAndReturn(func(context.Context, *sqs.GetQueueAttributesInput, ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error) {
+					current := running.Add(1)
+					defer running.Add(-1)
+
+					for {
+						previous := peak.Load()
+						if current <= previous || peak.CompareAndSwap(previous, current) {
+							break
+						}
+					}
+					time.Sleep(10 * time.Millisecond)
+
+					return &sqs.GetQueueAttributesOutput{Attributes: map[string]string{"ApproximateNumberOfMessages": "1", "ApproximateNumberOfMessagesNotVisible": "0"}}, nil
+				}).Times(3)
+
+				res, err := probe.Check(ctx)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(3))
+				Expect(peak.Load()).To(BeNumerically("<=", 2))
 			})
 		})
 	})
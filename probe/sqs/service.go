@@ -0,0 +1,120 @@
+package sqs
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsCfg "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"go.uber.org/zap"
+)
+
+const defaultSessionName = "autoscaler"
+
+// clientSettings identify AWS client, deployments with identical settings share single client
+type clientSettings struct {
+	region      string
+	endpointURL string
+	roleARN     string
+	externalID  string
+	sessionName string
+
+	cloudWatchEndpointURL string
+}
+
+func (c *Config) clientSettings() clientSettings {
+	settings := clientSettings{
+		region:      c.Region,
+		endpointURL: c.EndpointURL,
+		roleARN:     c.RoleARN,
+		externalID:  c.ExternalID,
+		sessionName: c.SessionName,
+
+		cloudWatchEndpointURL: c.CloudWatchEndpointURL,
+	}
+
+	if settings.roleARN != "" && settings.sessionName == "" {
+		settings.sessionName = defaultSessionName
+	}
+
+	return settings
+}
+
+// ServicePool shares SQS services between deployments, so assumed role credentials are cached once per settings
+type ServicePool struct {
+	mu       sync.Mutex
+	services map[clientSettings]*SQSService
+}
+
+func NewServicePool() *ServicePool {
+	return &ServicePool{
+		services: map[clientSettings]*SQSService{},
+	}
+}
+
+// Service returns SQS service for given config, creating it on the first use of its settings
+func (sp *ServicePool) Service(ctx context.Context, config *Config) (*SQSService, error) {
+	settings := config.clientSettings()
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	if s, ok := sp.services[settings]; ok {
+		return s, nil
+	}
+
+	s, err := newSQSService(ctx, settings)
+	if err != nil {
+		return s, err
+	}
+
+	sp.services[settings] = s
+
+	return s, nil
+}
+
+func newSQSService(ctx context.Context, settings clientSettings) (*SQSService, error) {
+	var options []func(*awsCfg.LoadOptions) error
+	if settings.region != "" {
+		options = append(options, awsCfg.WithRegion(settings.region))
+	}
+
+	cfg, err := awsCfg.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return &SQSService{}, err
+	}
+
+	if settings.roleARN != "" {
+		zap.S().Debugf("sqs client assumes role %v", settings.roleARN)
+
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), settings.roleARN, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = settings.sessionName
+			if settings.externalID != "" {
+				o.ExternalID = aws.String(settings.externalID)
+			}
+		})
+		// credentials are refreshed shortly before they expire
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	client := sqs.NewFromConfig(cfg, func(o *sqs.Options) {
+		if settings.endpointURL != "" {
+			o.BaseEndpoint = aws.String(settings.endpointURL)
+		}
+	})
+
+	cloudWatch := cloudwatch.NewFromConfig(cfg, func(o *cloudwatch.Options) {
+		if settings.cloudWatchEndpointURL != "" {
+			o.BaseEndpoint = aws.String(settings.cloudWatchEndpointURL)
+		}
+	})
+
+	return &SQSService{
+		Client:     client,
+		CloudWatch: cloudWatch,
+	}, nil
+}
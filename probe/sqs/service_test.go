@@ -0,0 +1,97 @@
+package sqs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/smithy-go/encoding/cbor"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ServicePool", func() {
+	var (
+		pool *ServicePool
+		ctx  = context.Background()
+	)
+
+	BeforeEach(func() {
+		GinkgoT().Setenv("AWS_ACCESS_KEY_ID", "test")
+		GinkgoT().Setenv("AWS_SECRET_ACCESS_KEY", "test")
+		GinkgoT().Setenv("AWS_REGION", "eu-west-1")
+
+		pool = NewServicePool()
+	})
+
+	It("Shares service between configs with identical settings", func() {
+		first, err := pool.Service(ctx, &Config{Queues: []QueueConfig{{Name: "q1"}}, Region: "us-east-1", RoleARN: "arn:aws:iam::123456789012:role/autoscaler"})
+		Expect(err).ToNot(HaveOccurred())
+
+		second, err := pool.Service(ctx, &Config{Queues: []QueueConfig{{Name: "q2"}}, Region: "us-east-1", RoleARN: "arn:aws:iam::123456789012:role/autoscaler"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(second).To(BeIdenticalTo(first))
+	})
+
+	It("Creates separate service for different settings", func() {
+		first, err := pool.Service(ctx, &Config{Region: "us-east-1"})
+		Expect(err).ToNot(HaveOccurred())
+
+		second, err := pool.Service(ctx, &Config{Region: "eu-central-1"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(second).ToNot(BeIdenticalTo(first))
+		Expect(second.Client.(*sqs.Client).Options().Region).To(Equal("eu-central-1"))
+	})
+
+	It("Sends requests to given endpoint", func() {
+		var target string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			target = r.Header.Get("X-Amz-Target")
+			w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+			_, _ = w.Write([]byte(`{"QueueUrl": "http://localhost:9324/000000000000/q1"}`))
+		}))
+		defer server.Close()
+
+		s, err := pool.Service(ctx, &Config{EndpointURL: server.URL})
+		Expect(err).ToNot(HaveOccurred())
+
+		output, err := s.Client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("q1")})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*output.QueueUrl).To(Equal("http://localhost:9324/000000000000/q1"))
+		Expect(target).To(Equal("AmazonSQS.GetQueueUrl"))
+	})
+
+	It("Sends metric requests to given cloudwatch endpoint", func() {
+		var path string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			w.Header().Set("Smithy-Protocol", "rpc-v2-cbor")
+			w.Header().Set("Content-Type", "application/cbor")
+			_, _ = w.Write(cbor.Encode(cbor.Map{
+				"MetricDataResults": cbor.List{
+					cbor.Map{"Id": cbor.String("q0"), "Values": cbor.List{cbor.Float64(1200)}},
+				},
+			}))
+		}))
+		defer server.Close()
+
+		s, err := pool.Service(ctx, &Config{CloudWatchEndpointURL: server.URL})
+		Expect(err).ToNot(HaveOccurred())
+
+		age := &messageAge{client: s.CloudWatch, max: 15 * time.Minute}
+		Expect(age.check(ctx, []string{"emails"})).To(Succeed())
+
+		Expect(path).To(HaveSuffix("/operation/GetMetricData"))
+		Expect(age.breach()).To(Equal("oldest message of queue `emails` is 20m0s old, over 15m0s"))
+	})
+
+	It("Uses default session name for assumed role", func() {
+		Expect((&Config{RoleARN: "arn:aws:iam::123456789012:role/autoscaler"}).clientSettings().sessionName).To(Equal("autoscaler"))
+		Expect((&Config{}).clientSettings().sessionName).To(BeEmpty())
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/AirHelp/autoscaler/probe/linked"
 	"github.com/AirHelp/autoscaler/probe/nginx"
 	"github.com/AirHelp/autoscaler/probe/redis"
 	"github.com/AirHelp/autoscaler/probe/sqs"
@@ -31,12 +32,25 @@ type Config struct {
 	Threshold      int           `yaml:"threshold"`
 
 	EnableEvents bool `yaml:"enable_events"`
+	PersistState bool `yaml:"persist_state"`
 
-	HourlyConfig []*HourlyConfig `yaml:"hourly_config"`
+	DeletionCost *DeletionCostConfig `yaml:"pod_deletion_cost"`
 
-	Sqs   *sqs.Config   `yaml:"sqs"`
-	Redis *redis.Config `yaml:"redis"`
-	Nginx *nginx.Config `yaml:"nginx"`
+	Priority   int `yaml:"priority"`
+	Guaranteed int `yaml:"guaranteed"`
+
+	HourlyConfig     []*HourlyConfig    `yaml:"hourly_config"`
+	ScheduledActions []*ScheduledAction `yaml:"scheduled_actions"`
+
+	Sqs         *sqs.Config          `yaml:"sqs"`
+	Redis       *redis.Config        `yaml:"redis"`
+	RedisStream *redis.StreamConfig  `yaml:"redis_stream"`
+	Sidekiq     *redis.SidekiqConfig `yaml:"sidekiq"`
+	BullMQ      *redis.BullMQConfig  `yaml:"bullmq"`
+	Celery      *redis.CeleryConfig  `yaml:"celery"`
+	Nginx       *nginx.Config        `yaml:"nginx"`
+
+	Linked *linked.Config `yaml:"linked"`
 }
 
 func NewScalerConfigWithDefaults() Config {
@@ -51,13 +65,21 @@ func NewScalerConfigWithDefaults() Config {
 	}
 }
 
+const defaultLimitsSource = "default"
+
 // Export `now` function to variable - make it available for stubbing in tests while not having massive hacks on code level
 var now = time.Now
 
 func (sc Config) ApplicableLimits() MinMaxConfig {
+	limits, _ := sc.applicableLimitsWithSource()
+	return limits
+}
+
+// applicableLimitsWithSource returns applicable limits and name of hourly config which set them
+func (sc Config) applicableLimitsWithSource() (MinMaxConfig, string) {
 	if len(sc.HourlyConfig) == 0 {
 		zap.S().Debug("no hourly configs defined, applying default")
-		return sc.MinMaxConfig
+		return sc.MinMaxConfig, defaultLimitsSource
 	}
 
 	hours, _, _ := now().Clock()
@@ -65,12 +87,12 @@ func (sc Config) ApplicableLimits() MinMaxConfig {
 	for _, hc := range sc.HourlyConfig {
 		if isHourWithinBoundaries(hours, hc.StartHour, hc.EndHour) {
 			zap.S().Debug(fmt.Sprintf("applying `%v` hourly config", hc.Name))
-			return hc.MinMaxConfig
+			return hc.MinMaxConfig, hc.Name
 		}
 	}
 
 	zap.S().Debug("none hourly config is applicable, fallback to default")
-	return sc.MinMaxConfig
+	return sc.MinMaxConfig, defaultLimitsSource
 }
 
 func isHourWithinBoundaries(hour, min, max int) bool {
@@ -0,0 +1,90 @@
+package scaler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/AirHelp/autoscaler/notification"
+	"github.com/AirHelp/autoscaler/probe"
+)
+
+// deadLetterAlertInterval limits how often growth of dead-letter queues is alerted, growth in between is reported with next alert
+const deadLetterAlertInterval = 5 * time.Minute
+
+// deadLetterDepths returns depths of dead-letter queues read by probe, they are never counted toward scaling
+func (s *Scaler) deadLetterDepths() map[string]int {
+	deadLetterProbe, ok := s.probe.(probe.DeadLetterProbe)
+	if !ok {
+		return nil
+	}
+
+	return maps.Clone(deadLetterProbe.DeadLetterDepths())
+}
+
+// checkDeadLetters alerts notifiers when any dead-letter queue grew. Depth seen for the first time is taken as baseline,
+// so messages which were already there when autoscaler started aren't alerted again.
+func (s *Scaler) checkDeadLetters(ctx context.Context, currentTime time.Time) {
+	depths := s.deadLetterDepths()
+	if len(depths) == 0 {
+		return
+	}
+
+	if s.deadLetterBaseline == nil {
+		s.deadLetterBaseline = map[string]int{}
+	}
+
+	var grown []string
+
+	for _, name := range slices.Sorted(maps.Keys(depths)) {
+		previous, ok := s.deadLetterBaseline[name]
+
+		switch {
+		case !ok:
+			s.deadLetterBaseline[name] = depths[name]
+		case depths[name] > previous:
+			grown = append(grown, fmt.Sprintf("%s grew from %d to %d", name, previous, depths[name]))
+		}
+	}
+
+	if len(grown) == 0 {
+		// drained queues lower baseline, so messages landing there again are alerted too
+		maps.Copy(s.deadLetterBaseline, depths)
+		return
+	}
+
+	scalerLogger := zap.S().With("deployment", s.deploymentName)
+	alert := fmt.Sprintf("messages landed in dead-letter queues: %s", strings.Join(grown, ", "))
+
+	if !s.lastDeadLetterAlertAt.IsZero() && currentTime.Sub(s.lastDeadLetterAlertAt) < deadLetterAlertInterval {
+		scalerLogger.Debugf("%s, alerted recently", alert)
+		return
+	}
+
+	scalerLogger.Warn(alert)
+
+	payload := notification.AlertPayload{
+		Alert:                    alert,
+		Environment:              s.globalConfig.Environment,
+		DeploymentName:           s.deploymentName,
+		Namespace:                s.globalConfig.Namespace,
+		RaisedAt:                 currentTime,
+		Source:                   s.probe.Kind(),
+		DeadLetterDepths:         depths,
+		PreviousDeadLetterDepths: maps.Clone(s.deadLetterBaseline),
+	}
+
+	maps.Copy(s.deadLetterBaseline, depths)
+	s.lastDeadLetterAlertAt = currentTime
+
+	for _, notifier := range s.notifiers {
+		if err := notifier.Alert(ctx, payload); err != nil {
+			scalerLogger.With("error", err).Warnf("failed to alert %v", notifier.Kind())
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package scaler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/AirHelp/autoscaler/notification"
+	notificationMock "github.com/AirHelp/autoscaler/notification/mock"
+	probeMock "github.com/AirHelp/autoscaler/probe/mock"
+)
+
+type deadLetterProbeStub struct {
+	*probeMock.MockProbe
+	depths map[string]int
+}
+
+func (p *deadLetterProbeStub) DeadLetterDepths() map[string]int {
+	return p.depths
+}
+
+var _ = Describe("Dead-letter queues", func() {
+	var (
+		mockCtrl     *gomock.Controller
+		notifierMock *notificationMock.MockNotifier
+		probeStub    *deadLetterProbeStub
+		sc           Scaler
+
+		ctx       = context.Background()
+		checkedAt = time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		notifierMock = notificationMock.NewMockNotifier(mockCtrl)
+
+		probeMock := probeMock.NewMockProbe(mockCtrl)
+		probeMock.EXPECT().Kind().Return("sqs").AnyTimes()
+		probeStub = &deadLetterProbeStub{MockProbe: probeMock, depths: map[string]int{"emails-dlq": 3, "pdfs-dlq": 0}}
+
+		sc = Scaler{
+			deploymentName: "test-deployment",
+			probe:          probeStub,
+			notifiers:      []notification.Notifier{notifierMock},
+		}
+	})
+
+	It("Takes depth seen for the first time as baseline", func() {
+		sc.checkDeadLetters(ctx, checkedAt)
+
+		Expect(sc.deadLetterBaseline).To(Equal(map[string]int{"emails-dlq": 3, "pdfs-dlq": 0}))
+	})
+
+	It("Alerts when dead-letter queue grows", func() {
+		sc.checkDeadLetters(ctx, checkedAt)
+
+		probeStub.depths = map[string]int{"emails-dlq": 3, "pdfs-dlq": 2}
+		notifierMock.EXPECT().Alert(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, payload notification.AlertPayload) error {
+			Expect(payload.Alert).To(Equal("messages landed in dead-letter queues: pdfs-dlq grew from 0 to 2"))
+			Expect(payload.DeploymentName).To(Equal("test-deployment"))
+			Expect(payload.RaisedAt).To(Equal(checkedAt.Add(time.Minute)))
+			Expect(payload.DeadLetterDepths).To(Equal(map[string]int{"emails-dlq": 3, "pdfs-dlq": 2}))
+			Expect(payload.PreviousDeadLetterDepths).To(Equal(map[string]int{"emails-dlq": 3, "pdfs-dlq": 0}))
+			return nil
+		})
+
+		sc.checkDeadLetters(ctx, checkedAt.Add(time.Minute))
+	})
+
+	It("Reports growth in between with next alert", func() {
+		sc.checkDeadLetters(ctx, checkedAt)
+
+		probeStub.depths = map[string]int{"emails-dlq": 4, "pdfs-dlq": 0}
+		notifierMock.EXPECT().Alert(ctx, gomock.Any()).Return(errors.New("slack is down"))
+		notifierMock.EXPECT().Kind().Return("slack")
+		sc.checkDeadLetters(ctx, checkedAt.Add(time.Minute))
+
+		probeStub.depths = map[string]int{"emails-dlq": 6, "pdfs-dlq": 0}
+		sc.checkDeadLetters(ctx, checkedAt.Add(2*time.Minute))
+
+		probeStub.depths = map[string]int{"emails-dlq": 7, "pdfs-dlq": 0}
+		notifierMock.EXPECT().Alert(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, payload notification.AlertPayload) error {
+			Expect(payload.Alert).To(Equal("messages landed in dead-letter queues: emails-dlq grew from 4 to 7"))
+			return nil
+		})
+		sc.checkDeadLetters(ctx, checkedAt.Add(time.Minute+deadLetterAlertInterval))
+	})
+
+	It("Alerts again when drained queue receives messages", func() {
+		sc.checkDeadLetters(ctx, checkedAt)
+
+		probeStub.depths = map[string]int{"emails-dlq": 0, "pdfs-dlq": 0}
+		sc.checkDeadLetters(ctx, checkedAt.Add(time.Minute))
+
+		probeStub.depths = map[string]int{"emails-dlq": 1, "pdfs-dlq": 0}
+		notifierMock.EXPECT().Alert(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, payload notification.AlertPayload) error {
+			Expect(payload.Alert).To(Equal("messages landed in dead-letter queues: emails-dlq grew from 0 to 1"))
+			return nil
+		})
+		sc.checkDeadLetters(ctx, checkedAt.Add(2*time.Minute))
+	})
+})
@@ -1,6 +1,11 @@
 package scaler
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+
+	"github.com/AirHelp/autoscaler/events"
+)
 
 const (
 	scaleUp = iota
@@ -11,16 +16,75 @@ const (
 type decision struct {
 	value   int
 	current int
+	desired int
 	target  int
+
+	// heldBy is a reason why scale up was held
+	heldBy string
+	// scheduledBy is a name of scheduled action which made decision
+	scheduledBy string
+
+	inputs events.DecisionInputs
+	gates  []events.DecisionGate
+}
+
+// gate records outcome of a check made before reaching decision
+func (d decision) gate(name string, passed bool, detail string) decision {
+	d.gates = append(slices.Clip(d.gates), events.DecisionGate{Gate: name, Passed: passed, Detail: detail})
+
+	return d
+}
+
+func (d decision) record(deploymentName string) *events.DecisionRecord {
+	action := events.ActionRemain
+	switch d.value {
+	case scaleUp:
+		action = events.ActionScaleUp
+	case scaleDown:
+		action = events.ActionScaleDown
+	}
+
+	return &events.DecisionRecord{
+		Deployment:      deploymentName,
+		Inputs:          d.inputs,
+		Gates:           d.gates,
+		Action:          action,
+		FromReplicas:    d.current,
+		ToReplicas:      d.target,
+		DesiredReplicas: d.desired,
+		HeldBy:          d.heldBy,
+		ScheduledAction: d.scheduledBy,
+		Summary:         d.toText(),
+	}
+}
+
+func (d decision) hold(reason string) decision {
+	d.value = remain
+	d.target = d.current
+	d.heldBy = reason
+
+	return d
 }
 
 func (d decision) toText() string {
+	if d.scheduledBy != "" && d.value != remain {
+		return fmt.Sprintf("%s (scheduled action `%s`)", d.changeText(), d.scheduledBy)
+	}
+
+	return d.changeText()
+}
+
+func (d decision) changeText() string {
 	switch d.value {
 	case scaleUp:
 		return fmt.Sprintf("scale up deployment from %d to %d replicas", d.current, d.target)
 	case scaleDown:
 		return fmt.Sprintf("scale down deployment from %d to %d replicas", d.current, d.target)
 	case remain:
+		if d.heldBy != "" {
+			return fmt.Sprintf("remain at %d replicas, scale up held: %s", d.current, d.heldBy)
+		}
+
 		return fmt.Sprintf("remain at %d replicas", d.current)
 	default:
 		return ""
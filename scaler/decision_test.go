@@ -3,6 +3,8 @@ package scaler
 import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	"github.com/AirHelp/autoscaler/events"
 )
 
 var _ = Describe("Decision", func() {
@@ -24,6 +26,49 @@ var _ = Describe("Decision", func() {
 				current: 5,
 				target:  5,
 			}, "remain at 5 replicas"),
+			Entry("When scale up held", decision{
+				value:   remain,
+				current: 5,
+				target:  5,
+				heldBy:  "resource_quota_exceeded",
+			}, "remain at 5 replicas, scale up held: resource_quota_exceeded"),
 		)
+
+		It("Records gates in order they were checked", func() {
+			d := decision{value: remain, current: 3, target: 3}.
+				gate(events.GateRollout, true, "").
+				gate(events.GateCooldown, false, "90s remaining")
+
+			Expect(d.record("test-deployment")).To(Equal(&events.DecisionRecord{
+				Deployment: "test-deployment",
+				Gates: []events.DecisionGate{
+					{Gate: events.GateRollout, Passed: true},
+					{Gate: events.GateCooldown, Passed: false, Detail: "90s remaining"},
+				},
+				Action:       events.ActionRemain,
+				FromReplicas: 3,
+				ToReplicas:   3,
+				Summary:      "remain at 3 replicas",
+			}))
+		})
+
+		It("Doesn't share gates between copies of decision", func() {
+			base := decision{}.gate(events.GateRollout, true, "").gate(events.GateCooldown, true, "")
+			first := base.gate(events.GateMaxLimit, true, "")
+			second := base.gate(events.GateMinLimit, true, "")
+
+			Expect(first.gates[2].Gate).To(Equal(events.GateMaxLimit))
+			Expect(second.gates[2].Gate).To(Equal(events.GateMinLimit))
+		})
+
+		It("Records held scale up", func() {
+			d := decision{value: scaleUp, current: 3, desired: 5, target: 4}.hold(events.ReasonPodBudgetExhausted)
+
+			record := d.record("test-deployment")
+			Expect(record.Action).To(Equal(events.ActionRemain))
+			Expect(record.ToReplicas).To(Equal(3))
+			Expect(record.DesiredReplicas).To(Equal(5))
+			Expect(record.HeldBy).To(Equal(events.ReasonPodBudgetExhausted))
+		})
 	})
 })
@@ -0,0 +1,127 @@
+package scaler
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/AirHelp/autoscaler/nginx_stats"
+	"github.com/AirHelp/autoscaler/probe"
+	"github.com/AirHelp/autoscaler/probe/nginx"
+)
+
+const (
+	podDeletionCostAnnotation = "controller.kubernetes.io/pod-deletion-cost"
+	busyEndpointTimeout       = 3 * time.Second
+)
+
+// DeletionCostConfig points to endpoint served by each pod returning how busy the pod is (eg. number of jobs in progress)
+type DeletionCostConfig struct {
+	Endpoint string `yaml:"endpoint"`
+	// Port is number or name of container port serving endpoint, default port of scheme is used when empty
+	Port   string `yaml:"port"`
+	Scheme string `yaml:"scheme"`
+	// Headers are sent with each request, eg. authorization
+	Headers map[string]string `yaml:"headers"`
+	TLS     nginx.TLSConfig   `yaml:"tls"`
+}
+
+// newClient creates client reading plain number from endpoint of each pod
+func (c *DeletionCostConfig) newClient() (*nginx_stats.NginxClient, error) {
+	tlsConfig, err := c.TLS.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return nginx_stats.NewClientWithConfig(nginx_stats.ClientConfig{
+		Endpoint: c.Endpoint,
+		Scheme:   c.Scheme,
+		Headers:  c.Headers,
+		TLS:      tlsConfig,
+	})
+}
+
+// setPodDeletionCosts annotates pods with their busyness right before scale down, so least busy pods are removed first
+func (s *Scaler) setPodDeletionCosts(ctx context.Context) {
+	scalerLogger := zap.S().With("deployment", s.deploymentName)
+
+	var costs map[string]int
+	var err error
+
+	switch costProbe, ok := s.probe.(probe.PodCostProbe); {
+	case s.busyClient != nil:
+		costs, err = s.fetchPodsBusyness(ctx)
+	case ok:
+		costs, err = costProbe.PodDeletionCosts(ctx)
+	default:
+		return
+	}
+
+	if err != nil {
+		scalerLogger.With("error", err).Warn("failed to determine pod deletion costs, pods to remove will be picked by k8s")
+		return
+	}
+
+	for pod, cost := range costs {
+		err := s.k8sService.AnnotatePod(ctx, pod, map[string]string{podDeletionCostAnnotation: strconv.Itoa(cost)})
+		if err != nil {
+			scalerLogger.With("error", err).Warnf("failed to set deletion cost of pod %v", pod)
+		}
+	}
+
+	scalerLogger.Debugf("set pod deletion costs: %+v", costs)
+}
+
+// fetchPodsBusyness returns busyness of running pods, pods which can't be reached are skipped and keep cost set by k8s
+func (s *Scaler) fetchPodsBusyness(ctx context.Context) (map[string]int, error) {
+	pods, err := s.k8sService.GetPodsFromDeployment(ctx, s.deployment, map[string]string{})
+	if err != nil {
+		return map[string]int{}, err
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = map[string]int{}
+	)
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(pod corev1.Pod) {
+			defer wg.Done()
+
+			requestCtx, cancel := context.WithTimeout(ctx, busyEndpointTimeout)
+			defer cancel()
+
+			var busy int
+
+			address, err := nginx.PodAddress(pod, s.scalerConfig.DeletionCost.Port)
+			if err == nil {
+				busy, err = s.busyClient.GetMetric(requestCtx, address)
+			}
+
+			if err != nil {
+				zap.S().With("deployment", s.deploymentName).With("pod", pod.Name).With("error", err).Warn("failed to fetch pod busyness, skipping pod")
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			results[pod.Name] = busy
+		}(pod)
+	}
+
+	wg.Wait()
+
+	return results, nil
+}
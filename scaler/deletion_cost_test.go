@@ -0,0 +1,186 @@
+package scaler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	probeMock "github.com/AirHelp/autoscaler/probe/mock"
+	"github.com/AirHelp/autoscaler/probe/nginx"
+	nginxMock "github.com/AirHelp/autoscaler/probe/nginx/mock"
+	scalerMock "github.com/AirHelp/autoscaler/scaler/mock"
+)
+
+type costProbeStub struct {
+	*probeMock.MockProbe
+	costs map[string]int
+	err   error
+}
+
+func (p costProbeStub) PodDeletionCosts(context.Context) (map[string]int, error) {
+	return p.costs, p.err
+}
+
+var _ = Describe("DeletionCost", func() {
+	var (
+		mockCtrl       *gomock.Controller
+		k8sServiceMock *scalerMock.MockK8SClient
+		busyClientMock *nginxMock.MockNginxClient
+
+		ctx        context.Context
+		deployment appsv1.Deployment
+		pods       *corev1.PodList
+		sc         Scaler
+
+		deploymentName = "test-deployment"
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mockCtrl = gomock.NewController(GinkgoT())
+		k8sServiceMock = scalerMock.NewMockK8SClient(mockCtrl)
+		busyClientMock = nginxMock.NewMockNginxClient(mockCtrl)
+
+		deployment = appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: deploymentName,
+			},
+		}
+
+		pods = &corev1.PodList{
+			Items: []corev1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "pod1"},
+					Status:     corev1.PodStatus{PodIP: "0.0.0.0", Phase: corev1.PodRunning},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "pod2"},
+					Status:     corev1.PodStatus{PodIP: "0.0.0.1", Phase: corev1.PodRunning},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "pod3"},
+					Status:     corev1.PodStatus{Phase: corev1.PodPending},
+				},
+			},
+		}
+
+		sc = Scaler{
+			deploymentName: deploymentName,
+			deployment:     &deployment,
+			k8sService:     k8sServiceMock,
+			probe:          probeMock.NewMockProbe(mockCtrl),
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Describe("setPodDeletionCosts()", func() {
+		Context("When busy endpoint is configured", func() {
+			BeforeEach(func() {
+				sc.busyClient = busyClientMock
+				sc.scalerConfig.DeletionCost = &DeletionCostConfig{Endpoint: "busy", Port: "8080"}
+			})
+
+			It("Annotates running pods with their busyness", func() {
+				k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, &deployment, map[string]string{}).Return(pods, nil)
+				busyClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.0:8080").Return(3, nil)
+				busyClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.1:8080").Return(0, nil)
+				k8sServiceMock.EXPECT().AnnotatePod(ctx, "pod1", map[string]string{podDeletionCostAnnotation: "3"})
+				k8sServiceMock.EXPECT().AnnotatePod(ctx, "pod2", map[string]string{podDeletionCostAnnotation: "0"})
+
+				sc.setPodDeletionCosts(ctx)
+			})
+
+			It("Annotates reachable pods when one of pods is unreachable", func() {
+				k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, &deployment, map[string]string{}).Return(pods, nil)
+				busyClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.0:8080").Return(3, nil)
+				busyClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.1:8080").Return(0, errors.New("connection refused"))
+				k8sServiceMock.EXPECT().AnnotatePod(ctx, "pod1", map[string]string{podDeletionCostAnnotation: "3"})
+
+				sc.setPodDeletionCosts(ctx)
+			})
+
+			It("Reads busyness from named container port", func() {
+				sc.scalerConfig.DeletionCost.Port = "busy"
+				pods.Items[0].Spec.Containers = []corev1.Container{{Ports: []corev1.ContainerPort{{Name: "busy", ContainerPort: 9000}}}}
+
+				k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, &deployment, map[string]string{}).Return(pods, nil)
+				busyClientMock.EXPECT().GetMetric(gomock.Any(), "0.0.0.0:9000").Return(3, nil)
+				k8sServiceMock.EXPECT().AnnotatePod(ctx, "pod1", map[string]string{podDeletionCostAnnotation: "3"})
+
+				sc.setPodDeletionCosts(ctx)
+			})
+
+			It("Does not annotate pods when pods can't be listed", func() {
+				k8sServiceMock.EXPECT().GetPodsFromDeployment(ctx, &deployment, map[string]string{}).Return(nil, errors.New("forbidden"))
+
+				sc.setPodDeletionCosts(ctx)
+			})
+		})
+
+		Context("When probe knows pods busyness", func() {
+			It("Annotates pods with costs from probe", func() {
+				sc.probe = costProbeStub{costs: map[string]int{"pod1": 120}}
+
+				k8sServiceMock.EXPECT().AnnotatePod(ctx, "pod1", map[string]string{podDeletionCostAnnotation: "120"})
+
+				sc.setPodDeletionCosts(ctx)
+			})
+
+			It("Does not annotate pods when probe fails", func() {
+				sc.probe = costProbeStub{err: errors.New("deployment not fully operational")}
+
+				sc.setPodDeletionCosts(ctx)
+			})
+		})
+
+		It("Does nothing when pod busyness is unknown", func() {
+			sc.setPodDeletionCosts(ctx)
+		})
+	})
+
+	Describe("newClient()", func() {
+		It("Reads busyness over https with headers", func() {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/busy" || r.Header.Get("Authorization") != "Bearer secret" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				w.Write([]byte("3"))
+			}))
+			DeferCleanup(server.Close)
+
+			config := DeletionCostConfig{
+				Endpoint: "/busy",
+				Scheme:   "https",
+				Headers:  map[string]string{"Authorization": "Bearer secret"},
+				TLS:      nginx.TLSConfig{InsecureSkipVerify: true},
+			}
+
+			client, err := config.newClient()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.GetMetric(context.Background(), strings.TrimPrefix(server.URL, "https://"))).To(Equal(3))
+		})
+
+		It("Returns error when CA file is missing", func() {
+			config := DeletionCostConfig{Endpoint: "/busy", Scheme: "https", TLS: nginx.TLSConfig{CAFile: "/nonexistent/ca.pem"}}
+
+			_, err := config.newClient()
+
+			Expect(err).To(MatchError(ContainSubstring("failed to read CA file")))
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package scaler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateLinks checks configs of linked deployments. Deployment is rejected when its source deployment isn't managed
+// by autoscaler, when it is part of a dependency cycle or when its source deployment is rejected.
+// Returns errors of rejected deployments.
+func ValidateLinks(configs map[string]Config) map[string]error {
+	errs := map[string]error{}
+
+	for name, config := range configs {
+		if config.Linked == nil {
+			continue
+		}
+
+		if _, ok := configs[config.Linked.Deployment]; !ok {
+			errs[name] = fmt.Errorf("source deployment %v is not managed by autoscaler", config.Linked.Deployment)
+			continue
+		}
+
+		if cycle := findCycle(name, configs); cycle != nil {
+			errs[name] = fmt.Errorf("dependency cycle: %v", strings.Join(cycle, " -> "))
+		}
+	}
+
+	for rejected := true; rejected; {
+		rejected = false
+
+		for name, config := range configs {
+			if _, ok := errs[name]; ok || config.Linked == nil {
+				continue
+			}
+
+			if _, ok := errs[config.Linked.Deployment]; ok {
+				errs[name] = fmt.Errorf("source deployment %v is rejected", config.Linked.Deployment)
+				rejected = true
+			}
+		}
+	}
+
+	return errs
+}
+
+// findCycle follows sources of linked deployments and returns the path when it leads back to the start deployment
+func findCycle(start string, configs map[string]Config) []string {
+	path := []string{start}
+	visited := map[string]bool{start: true}
+
+	for config, ok := configs[start]; ok && config.Linked != nil; config, ok = configs[config.Linked.Deployment] {
+		source := config.Linked.Deployment
+		path = append(path, source)
+
+		if source == start {
+			return path
+		}
+
+		if visited[source] {
+			return nil
+		}
+
+		visited[source] = true
+	}
+
+	return nil
+}
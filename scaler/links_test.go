@@ -0,0 +1,65 @@
+package scaler
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/AirHelp/autoscaler/probe/linked"
+)
+
+var _ = Describe("Links", func() {
+	Describe("ValidateLinks()", func() {
+		linkedTo := func(source string) Config {
+			return Config{Linked: &linked.Config{Deployment: source, Ratio: 0.25}}
+		}
+
+		It("Accepts linked deployments without cycles", func() {
+			errs := ValidateLinks(map[string]Config{
+				"claims-worker":        {},
+				"claims-pdf-renderer":  linkedTo("claims-worker"),
+				"claims-pdf-archiver":  linkedTo("claims-pdf-renderer"),
+				"unrelated-deployment": {},
+			})
+
+			Expect(errs).To(BeEmpty())
+		})
+
+		It("Rejects deployment linked to not managed deployment", func() {
+			errs := ValidateLinks(map[string]Config{
+				"claims-pdf-renderer": linkedTo("claims-worker"),
+			})
+
+			Expect(errs).To(Equal(map[string]error{
+				"claims-pdf-renderer": fmt.Errorf("source deployment claims-worker is not managed by autoscaler"),
+			}))
+		})
+
+		It("Rejects deployment linked to itself", func() {
+			errs := ValidateLinks(map[string]Config{
+				"claims-worker": linkedTo("claims-worker"),
+			})
+
+			Expect(errs).To(Equal(map[string]error{
+				"claims-worker": fmt.Errorf("dependency cycle: claims-worker -> claims-worker"),
+			}))
+		})
+
+		It("Rejects deployments in cycle and deployments depending on them", func() {
+			errs := ValidateLinks(map[string]Config{
+				"a": linkedTo("b"),
+				"b": linkedTo("c"),
+				"c": linkedTo("b"),
+				"d": linkedTo("a"),
+			})
+
+			Expect(errs).To(Equal(map[string]error{
+				"a": fmt.Errorf("source deployment b is rejected"),
+				"b": fmt.Errorf("dependency cycle: b -> c -> b"),
+				"c": fmt.Errorf("dependency cycle: c -> b -> c"),
+				"d": fmt.Errorf("source deployment a is rejected"),
+			}))
+		})
+	})
+})
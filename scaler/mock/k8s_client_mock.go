@@ -37,6 +37,63 @@ func (m *MockK8SClient) EXPECT() *MockK8SClientMockRecorder {
 	return m.recorder
 }
 
+// AnnotateDeployment mocks base method.
+func (m *MockK8SClient) AnnotateDeployment(arg0 context.Context, arg1 string, arg2 map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnnotateDeployment", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnnotateDeployment indicates an expected call of AnnotateDeployment.
+func (mr *MockK8SClientMockRecorder) AnnotateDeployment(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnnotateDeployment", reflect.TypeOf((*MockK8SClient)(nil).AnnotateDeployment), arg0, arg1, arg2)
+}
+
+// AnnotatePod mocks base method.
+func (m *MockK8SClient) AnnotatePod(arg0 context.Context, arg1 string, arg2 map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnnotatePod", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnnotatePod indicates an expected call of AnnotatePod.
+func (mr *MockK8SClientMockRecorder) AnnotatePod(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnnotatePod", reflect.TypeOf((*MockK8SClient)(nil).AnnotatePod), arg0, arg1, arg2)
+}
+
+// CheckScaleUpCapacity mocks base method.
+func (m *MockK8SClient) CheckScaleUpCapacity(arg0 context.Context, arg1 *v1.Deployment, arg2 int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckScaleUpCapacity", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckScaleUpCapacity indicates an expected call of CheckScaleUpCapacity.
+func (mr *MockK8SClientMockRecorder) CheckScaleUpCapacity(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckScaleUpCapacity", reflect.TypeOf((*MockK8SClient)(nil).CheckScaleUpCapacity), arg0, arg1, arg2)
+}
+
+// CreateScalingEvent mocks base method.
+func (m *MockK8SClient) CreateScalingEvent(arg0 context.Context, arg1 *v1.Deployment, arg2 *events.ScalingEventData) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScalingEvent", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateScalingEvent indicates an expected call of CreateScalingEvent.
+func (mr *MockK8SClientMockRecorder) CreateScalingEvent(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScalingEvent", reflect.TypeOf((*MockK8SClient)(nil).CreateScalingEvent), arg0, arg1, arg2)
+}
+
 // GetDeployment mocks base method.
 func (m *MockK8SClient) GetDeployment(arg0 context.Context, arg1 string) (*v1.Deployment, error) {
 	m.ctrl.T.Helper()
@@ -81,17 +138,3 @@ func (mr *MockK8SClientMockRecorder) ScaleDeployment(arg0, arg1, arg2 interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScaleDeployment", reflect.TypeOf((*MockK8SClient)(nil).ScaleDeployment), arg0, arg1, arg2)
 }
-
-// CreateScalingEvent mocks base method.
-func (m *MockK8SClient) CreateScalingEvent(arg0 context.Context, arg1 *v1.Deployment, arg2 *events.ScalingEventData) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateScalingEvent", arg0, arg1, arg2)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// CreateScalingEvent indicates an expected call of CreateScalingEvent.
-func (mr *MockK8SClientMockRecorder) CreateScalingEvent(arg0, arg1, arg2 interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScalingEvent", reflect.TypeOf((*MockK8SClient)(nil).CreateScalingEvent), arg0, arg1, arg2)
-}
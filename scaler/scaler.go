@@ -3,7 +3,10 @@ package scaler
 import (
 	"context"
 	"errors"
+	"fmt"
+	"maps"
 	"math"
+	"slices"
 	"time"
 
 	"gopkg.in/yaml.v2"
@@ -11,11 +14,13 @@ import (
 	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
 
+	"github.com/AirHelp/autoscaler/budget"
 	"github.com/AirHelp/autoscaler/config"
 	"github.com/AirHelp/autoscaler/events"
 	"github.com/AirHelp/autoscaler/helper"
 	"github.com/AirHelp/autoscaler/notification"
 	"github.com/AirHelp/autoscaler/probe"
+	"github.com/AirHelp/autoscaler/probe/linked"
 	"github.com/AirHelp/autoscaler/probe/nginx"
 	"github.com/AirHelp/autoscaler/probe/redis"
 	"github.com/AirHelp/autoscaler/probe/sqs"
@@ -35,9 +40,23 @@ type Scaler struct {
 	lastTenResults []int
 	lastActionAt   time.Time
 
+	// persistedState is state stored in deployment annotation, deployment is patched only when it changes
+	persistedState string
+
+	lastScheduleCheckAt time.Time
+
+	// lastHeldBy is a reason scale up was held by at the last check, event is created only when it changes
+	lastHeldBy string
+
+	deadLetterBaseline    map[string]int
+	lastDeadLetterAlertAt time.Time
+
 	k8sService K8SClient
 	sqsService *sqs.SQSService
 	notifiers  []notification.Notifier
+	podBudget  *budget.Arbiter
+	tracker    ReplicasChangeTracker
+	busyClient nginx.NginxClient
 
 	globalConfig config.Config
 }
@@ -51,6 +70,8 @@ type NewScalerInput struct {
 	K8sService K8SClient
 	SQSService *sqs.SQSService
 	Notifiers  []notification.Notifier
+	PodBudget  *budget.Arbiter
+	Tracker    ReplicasChangeTracker
 
 	GlobalConfig config.Config
 }
@@ -60,11 +81,22 @@ type K8SClient interface {
 	GetDeployment(context.Context, string) (*appsv1.Deployment, error)
 	ScaleDeployment(context.Context, *appsv1.Deployment, int) (*appsv1.Deployment, error)
 	CreateScalingEvent(context.Context, *appsv1.Deployment, *events.ScalingEventData) error
+	CheckScaleUpCapacity(context.Context, *appsv1.Deployment, int) (string, error)
+	AnnotateDeployment(context.Context, string, map[string]string) error
+	AnnotatePod(context.Context, string, map[string]string) error
 
 	nginx.K8SClient
 }
 
-var ErrProbeNotSpecified = errors.New("no probe specified for autoscaler")
+// ReplicasChangeTracker knows when deployment replicas were changed, including changes made by other autoscaler instances
+type ReplicasChangeTracker interface {
+	LastReplicasChangeAt(string) time.Time
+}
+
+var (
+	ErrProbeNotSpecified                = errors.New("no probe specified for autoscaler")
+	ErrDeletionCostEndpointNotSpecified = errors.New("no endpoint specified for pod deletion cost")
+)
 
 func New(i NewScalerInput) (*Scaler, error) {
 	s := Scaler{
@@ -73,6 +105,8 @@ func New(i NewScalerInput) (*Scaler, error) {
 		k8sService:     i.K8sService,
 		globalConfig:   i.GlobalConfig,
 		sqsService:     i.SQSService,
+		podBudget:      i.PodBudget,
+		tracker:        i.Tracker,
 	}
 	scalerLogger := zap.S().With("deployment", i.DeploymentName)
 
@@ -95,16 +129,37 @@ func New(i NewScalerInput) (*Scaler, error) {
 	s.scalerConfig = scalerConfig
 	scalerLogger.Debugf("parsed autoscaler config: %+v", scalerConfig)
 
+	if s.scalerConfig.DeletionCost != nil {
+		if s.scalerConfig.DeletionCost.Endpoint == "" {
+			return &s, ErrDeletionCostEndpointNotSpecified
+		}
+
+		s.busyClient, err = s.scalerConfig.DeletionCost.newClient()
+		if err != nil {
+			return &s, err
+		}
+	}
+
 	var requestedProbe probe.Probe
 	scalerLogger.Debug("initializing probe")
 
 	switch {
 	case s.scalerConfig.Sqs != nil:
-		requestedProbe, err = sqs.New(i.Ctx, s.scalerConfig.Sqs, s.sqsService)
+		requestedProbe, err = sqs.New(i.Ctx, s.scalerConfig.Sqs, s.sqsService, s.scalerConfig.Threshold)
 	case s.scalerConfig.Redis != nil:
 		requestedProbe, err = redis.New(s.scalerConfig.Redis)
+	case s.scalerConfig.RedisStream != nil:
+		requestedProbe, err = redis.NewStream(s.scalerConfig.RedisStream)
+	case s.scalerConfig.Sidekiq != nil:
+		requestedProbe, err = redis.NewSidekiq(s.scalerConfig.Sidekiq)
+	case s.scalerConfig.BullMQ != nil:
+		requestedProbe, err = redis.NewBullMQ(s.scalerConfig.BullMQ)
+	case s.scalerConfig.Celery != nil:
+		requestedProbe, err = redis.NewCelery(s.scalerConfig.Celery)
 	case s.scalerConfig.Nginx != nil:
 		requestedProbe, err = nginx.New(s.scalerConfig.Nginx, i.K8sService, s.deployment)
+	case s.scalerConfig.Linked != nil:
+		requestedProbe, err = linked.New(s.scalerConfig.Linked, i.K8sService)
 	default:
 		return &s, ErrProbeNotSpecified
 	}
@@ -116,11 +171,31 @@ func New(i NewScalerInput) (*Scaler, error) {
 	s.probe = requestedProbe
 	scalerLogger.Debugf("initialized probe: %s", s.probe.Kind())
 
+	// registered last, so scaler which fails to start doesn't hold pods of the budget
+	if s.podBudget != nil {
+		err = s.podBudget.Register(s.deploymentName, s.scalerConfig.Priority, s.scalerConfig.Guaranteed, int(*s.deployment.Spec.Replicas))
+		if err != nil {
+			scalerLogger.With("error", err).Warn("failed to register in pod budget")
+			return &s, err
+		}
+	}
+
 	return &s, nil
 }
 
 func (s *Scaler) Start(ctx context.Context) {
 	scalerLogger := zap.S().With("deployment", s.deploymentName)
+
+	// Start runs each time leadership is acquired, state may have been changed by previous leader meanwhile
+	if s.scalerConfig.PersistState {
+		if err := s.refreshDeployment(ctx); err != nil {
+			scalerLogger.With("error", err).Warnf("failed to refresh deployment, restoring state known before: %v", err)
+		}
+
+		s.restoreState()
+	}
+
+	s.restoreCooldown()
 	ticker := time.NewTicker(s.scalerConfig.CheckInterval)
 
 	for {
@@ -139,11 +214,29 @@ func (s *Scaler) Start(ctx context.Context) {
 	}
 }
 
+// restoreCooldown takes over cooldown from replicas changes made while this instance wasn't scaling, eg. by previous leader
+func (s *Scaler) restoreCooldown() {
+	if s.tracker == nil {
+		return
+	}
+
+	if changedAt := s.tracker.LastReplicasChangeAt(s.deploymentName); changedAt.After(s.lastActionAt) {
+		zap.S().With("deployment", s.deploymentName).Debugf("restoring cooldown from replicas change at %v", changedAt)
+		s.lastActionAt = changedAt
+	}
+}
+
 func (s *Scaler) perform(ctx context.Context) {
 	scalerLogger := zap.S().With("deployment", s.deploymentName)
 	scalerLogger.Debug("starting to evaluate autoscaling needs")
 
-	currentTime := time.Now()
+	currentTime := now()
+
+	if action := s.dueScheduledAction(currentTime); action != nil {
+		s.performScheduledAction(ctx, action, currentTime)
+		return
+	}
+	s.lastScheduleCheckAt = currentTime
 
 	probeResult, err := s.probe.Check(ctx)
 	if err != nil {
@@ -152,6 +245,13 @@ func (s *Scaler) perform(ctx context.Context) {
 	}
 
 	scalerLogger.Debugf("probe %s returned %d", s.probe.Kind(), probeResult)
+
+	s.checkDeadLetters(ctx, currentTime)
+
+	if s.scalerConfig.PersistState {
+		defer s.persistState(ctx)
+	}
+
 	s.lastTenResults = append(s.lastTenResults, probeResult)
 	s.lastTenResults = helper.Last(s.lastTenResults, resultsToStore)
 	scalerLogger.Debugf("last 10 probe runs %+v", s.lastTenResults)
@@ -161,98 +261,228 @@ func (s *Scaler) perform(ctx context.Context) {
 		return
 	}
 
+	d := s.newDecision(probeResult)
+
 	if s.isDeploymentNotAtTargetReplicas() {
 		scalerLogger.Warn("deployment available replicas not at target. won't adjust")
+		detail := fmt.Sprintf("%d of %d replicas available", s.deployment.Status.AvailableReplicas, s.deployment.Status.Replicas)
+
+		if blocker, err := s.k8sService.CheckScaleUpCapacity(ctx, s.deployment, 0); err == nil && blocker != "" {
+			scalerLogger.Warnf("deployment can't reach target replicas: %s", blocker)
+			detail = fmt.Sprintf("%s, %s", detail, blocker)
+			d = d.hold(blocker)
+		}
+
+		d = d.gate(events.GateRollout, false, detail)
+		s.reportHold(ctx, d, probeResult)
+		s.logDecision(d)
 		return
 	}
+	d = d.gate(events.GateRollout, true, "")
+
+	_, linkedMode := s.probe.(probe.ReplicasProbe)
 
-	if s.isAutoscalerInCooldown(currentTime) {
+	switch {
+	case linkedMode:
+		// linked deployment follows its source deployment which applies its own cooldown
+		d = d.gate(events.GateCooldown, true, "not applied to linked deployment")
+	case s.isAutoscalerInCooldown(currentTime):
 		scalerLogger.Debug("autoscaler in cooldown, not making decision")
+		s.logDecision(d.gate(events.GateCooldown, false, fmt.Sprintf("%.0fs remaining", d.inputs.CooldownRemainingSeconds)))
 		return
+	default:
+		d = d.gate(events.GateCooldown, true, "")
 	}
 
 	decision := s.calculateDecision(probeResult)
-	scalerLogger.Infof("decision: %s", decision.toText())
+	// gates checked before calculation go first
+	decision.gates = append(d.gates, decision.gates...)
+	decision = s.applyProbeFailuresCheck(decision)
+	decision = s.applyGates(ctx, decision, probeResult)
+	s.logDecision(decision)
 
 	if decision.value != remain {
-		_, err = s.k8sService.ScaleDeployment(ctx, s.deployment, decision.target)
+		s.executeDecision(ctx, decision, probeResult, currentTime)
+	}
 
-		if err != nil {
-			scalerLogger.With("error", err).Warn("updating replication failed")
-		}
+	scalerLogger.Debug("finished evaluating autoscaling needs")
+}
 
-		if s.scalerConfig.EnableEvents {
-			eventData := s.buildEventData(decision, probeResult)
-			
-			if err := s.k8sService.CreateScalingEvent(ctx, s.deployment, eventData); err != nil {
-				scalerLogger.With("error", err).Warn("failed to create scaling event")
-			} else {
-				scalerLogger.Infof("created rich Kubernetes event: %s (load: %.1f%%)", eventData.ScalingDirection, eventData.LoadPercentage)
-			}
+// applyGates holds or limits scale up when pod budget or namespace capacity doesn't allow it
+func (s *Scaler) applyGates(ctx context.Context, d decision, probeResult int) decision {
+	d = s.applyPodBudget(d)
+	d = s.applyCapacityCheck(ctx, d)
+	s.reportHold(ctx, d, probeResult)
+
+	return d
+}
+
+// reportHold creates warning event when scale up gets held or is held by other reason than at the last check,
+// so hold lasting many checks doesn't flood namespace with events
+func (s *Scaler) reportHold(ctx context.Context, d decision, probeResult int) {
+	previous := s.lastHeldBy
+	s.lastHeldBy = d.heldBy
+
+	if d.heldBy == "" || d.heldBy == previous || !s.scalerConfig.EnableEvents {
+		return
+	}
+
+	eventData := s.buildEventData(d, probeResult)
+
+	if err := s.k8sService.CreateScalingEvent(ctx, s.deployment, eventData); err != nil {
+		zap.S().With("deployment", s.deploymentName).With("error", err).Warn("failed to create scaling event")
+		// retried at the next check
+		s.lastHeldBy = previous
+	}
+}
+
+// logDecision emits decision along with its explanation as a single log line
+func (s *Scaler) logDecision(d decision) {
+	zap.S().With("deployment", s.deploymentName).With("explanation", d.record(s.deploymentName)).Infof("decision: %s", d.toText())
+}
+
+// executeDecision scales deployment, returned error tells scaling deployment failed
+func (s *Scaler) executeDecision(ctx context.Context, decision decision, probeResult int, currentTime time.Time) error {
+	scalerLogger := zap.S().With("deployment", s.deploymentName)
+
+	if decision.value == scaleDown {
+		s.setPodDeletionCosts(ctx)
+	}
+
+	_, scaleErr := s.k8sService.ScaleDeployment(ctx, s.deployment, decision.target)
+
+	if scaleErr != nil {
+		scalerLogger.With("error", scaleErr).Warn("updating replication failed")
+	}
+
+	if s.scalerConfig.EnableEvents {
+		eventData := s.buildEventData(decision, probeResult)
+
+		if err := s.k8sService.CreateScalingEvent(ctx, s.deployment, eventData); err != nil {
+			scalerLogger.With("error", err).Warn("failed to create scaling event")
+		} else {
+			scalerLogger.Infof("created rich Kubernetes event: %s (load: %.1f%%)", eventData.ScalingDirection, eventData.LoadPercentage)
 		}
+	}
 
-		s.lastActionAt = currentTime
-
-		if len(s.notifiers) > 0 {
-			notificationPayload := notification.NotificationPayload{
-				Decision:         decision.toText(),
-				LastProbeResults: s.lastTenResults,
-				DeploymentName:   s.deployment.GetName(),
-				ChangedAt:        currentTime,
-				Source:           s.probe.Kind(),
-				Namespace:        s.globalConfig.Namespace,
-				Environment:      s.globalConfig.Environment,
-			}
+	s.lastActionAt = currentTime
+
+	if len(s.notifiers) > 0 {
+		notificationPayload := notification.NotificationPayload{
+			Decision:         decision.toText(),
+			LastProbeResults: s.lastTenResults,
+			DeploymentName:   s.deployment.GetName(),
+			ChangedAt:        currentTime,
+			Source:           s.probe.Kind(),
+			Namespace:        s.globalConfig.Namespace,
+			Environment:      s.globalConfig.Environment,
+			Explanation:      decision.record(s.deploymentName),
+			DeadLetterDepths: s.deadLetterDepths(),
+		}
 
-			for _, notifier := range s.notifiers {
-				if err := notifier.Notify(ctx, notificationPayload); err != nil {
-					scalerLogger.With("error", err).Warnf("failed to notify %v", notifier.Kind())
-				}
+		for _, notifier := range s.notifiers {
+			if err := notifier.Notify(ctx, notificationPayload); err != nil {
+				scalerLogger.With("error", err).Warnf("failed to notify %v", notifier.Kind())
 			}
 		}
 	}
 
-	scalerLogger.Debug("finished evaluating autoscaling needs")
+	return scaleErr
 }
 
-func (s *Scaler) calculateDecision(probeResult int) decision {
-	scalerLogger := zap.S().With("deployment", s.deploymentName)
+// newDecision starts decision to remain at current replicas, recording inputs it is based on
+func (s *Scaler) newDecision(probeResult int) decision {
 	currentReplicasCount := int(*s.deployment.Spec.Replicas)
+	minMaxConfig, limitsSource := s.scalerConfig.applicableLimitsWithSource()
+
+	var cooldownRemaining time.Duration
+	if !s.lastActionAt.IsZero() {
+		cooldownRemaining = max(s.lastActionAt.Add(s.scalerConfig.CooldownPeriod).Sub(now()), 0)
+	}
 
 	d := decision{
-		current: currentReplicasCount,
 		value:   remain,
+		current: currentReplicasCount,
+		desired: currentReplicasCount,
 		target:  currentReplicasCount,
+		inputs: events.DecisionInputs{
+			ProbeType:                s.probe.Kind(),
+			ProbeValue:               probeResult,
+			ProbeHistory:             slices.Clone(s.lastTenResults),
+			Threshold:                s.scalerConfig.Threshold,
+			CurrentReplicas:          currentReplicasCount,
+			AvailableReplicas:        int(s.deployment.Status.AvailableReplicas),
+			MinPods:                  minMaxConfig.MinimumNumberOfPods,
+			MaxPods:                  minMaxConfig.MaximumNumberOfPods,
+			LimitsSource:             limitsSource,
+			CooldownRemainingSeconds: cooldownRemaining.Seconds(),
+		},
+	}
+
+	if breakdownProbe, ok := s.probe.(probe.BreakdownProbe); ok {
+		d.inputs.ProbeBreakdown = maps.Clone(breakdownProbe.Breakdown())
+	}
+
+	if partialProbe, ok := s.probe.(probe.PartialProbe); ok && len(partialProbe.Failures()) > 0 {
+		d.inputs.ProbeFailures = maps.Clone(partialProbe.Failures())
+	}
+
+	if latencyProbe, ok := s.probe.(probe.LatencyProbe); ok {
+		d.inputs.LatencyBreach = latencyProbe.LatencyBreach()
+	}
+
+	return d
+}
+
+func (s *Scaler) calculateDecision(probeResult int) decision {
+	d := s.newDecision(probeResult)
+
+	if replicasProbe, ok := s.probe.(probe.ReplicasProbe); ok {
+		return s.calculateLinkedDecision(d, replicasProbe.DesiredReplicas(probeResult))
 	}
 
+	scalerLogger := zap.S().With("deployment", s.deploymentName)
+	currentReplicasCount := d.current
+	minPods, maxPods := d.inputs.MinPods, d.inputs.MaxPods
+
 	desiredReplicasCount := int(math.Ceil(float64(probeResult) / float64(s.scalerConfig.Threshold)))
 
+	if d.inputs.LatencyBreach != "" && desiredReplicasCount <= currentReplicasCount {
+		scalerLogger.Infof("%s, scaling up regardless of probe result", d.inputs.LatencyBreach)
+		desiredReplicasCount = currentReplicasCount + 1
+	}
+
 	scalerLogger.Debugf("current replicas count: %d, desired replicas count: %d", probeResult, desiredReplicasCount)
 
-	minMaxConfig := s.scalerConfig.ApplicableLimits()
+	d.desired = helper.Min(helper.Max(desiredReplicasCount, minPods), maxPods)
 
 	if currentReplicasCount == desiredReplicasCount {
 		scalerLogger.Debug("current replicas same as desired, deployment remain the same")
 	} else if currentReplicasCount < desiredReplicasCount {
 		scalerLogger.Debug("current replicas lower than desired")
-		if currentReplicasCount+1 <= minMaxConfig.MaximumNumberOfPods {
+		if currentReplicasCount+1 <= maxPods {
 			scalerLogger.Debug("scale up available, decided to scale up")
+			d = d.gate(events.GateMaxLimit, true, "")
 			d.value = scaleUp
 			d.target = currentReplicasCount + 1
 		} else {
 			scalerLogger.Debug("scale up unavailable, reached maximum number of pods")
+			d = d.gate(events.GateMaxLimit, false, fmt.Sprintf("maximum of %d pods set by %s limits", maxPods, d.inputs.LimitsSource))
 		}
 	} else if currentReplicasCount > desiredReplicasCount {
 		scalerLogger.Debug("current replicas higher than desired")
-		if currentReplicasCount-1 >= minMaxConfig.MinimumNumberOfPods {
+		if currentReplicasCount-1 >= minPods {
+			d = d.gate(events.GateMinLimit, true, "")
 			if currentReplicasCount-1 == 0 {
 				// Check if last `consecutiveZerosToZeroDeployment` are zero read outs
 				if helper.OnlyZeros(helper.Last(s.lastTenResults, consecutiveZerosToZeroDeployment)) {
 					scalerLogger.Debug("scalling down to zero, consecutive zero reads")
+					d = d.gate(events.GateConsecutiveZeros, true, "")
 					d.value = scaleDown
 					d.target = currentReplicasCount - 1
 				} else {
 					scalerLogger.Debug("scaling down to zero unavailable, no consecutive zero reads")
+					d = d.gate(events.GateConsecutiveZeros, false, fmt.Sprintf("%d consecutive zero readouts required", consecutiveZerosToZeroDeployment))
 				}
 			} else {
 				scalerLogger.Debug("scale down available, decided to scale down")
@@ -261,12 +491,105 @@ func (s *Scaler) calculateDecision(probeResult int) decision {
 			}
 		} else {
 			scalerLogger.Debug("scale down unavailable, reached minimum number of pods")
+			d = d.gate(events.GateMinLimit, false, fmt.Sprintf("minimum of %d pods set by %s limits", minPods, d.inputs.LimitsSource))
 		}
 	}
 
 	return d
 }
 
+// calculateLinkedDecision scales directly to desired replicas within applicable limits
+func (s *Scaler) calculateLinkedDecision(d decision, desiredReplicasCount int) decision {
+	minPods, maxPods := d.inputs.MinPods, d.inputs.MaxPods
+	target := helper.Min(helper.Max(desiredReplicasCount, minPods), maxPods)
+
+	zap.S().With("deployment", s.deploymentName).Debugf("linked deployment desired replicas count: %d, within limits: %d", desiredReplicasCount, target)
+
+	switch {
+	case desiredReplicasCount > maxPods:
+		d = d.gate(events.GateMaxLimit, false, fmt.Sprintf("maximum of %d pods set by %s limits", maxPods, d.inputs.LimitsSource))
+	case desiredReplicasCount < minPods:
+		d = d.gate(events.GateMinLimit, false, fmt.Sprintf("minimum of %d pods set by %s limits", minPods, d.inputs.LimitsSource))
+	}
+
+	d.desired = target
+	d.target = target
+
+	switch {
+	case target > d.current:
+		d.value = scaleUp
+	case target < d.current:
+		d.value = scaleDown
+	}
+
+	return d
+}
+
+// applyProbeFailuresCheck keeps replicas when probe result is partial, as missing parts could still have load
+func (s *Scaler) applyProbeFailuresCheck(d decision) decision {
+	if d.value != scaleDown {
+		return d
+	}
+
+	if len(d.inputs.ProbeFailures) == 0 {
+		return d.gate(events.GateProbeFailures, true, "")
+	}
+
+	zap.S().With("deployment", s.deploymentName).Warnf("scale down skipped, probe failed for %d of its parts", len(d.inputs.ProbeFailures))
+
+	d.value = remain
+	d.target = d.current
+
+	return d.gate(events.GateProbeFailures, false, fmt.Sprintf("%d parts of probe failed", len(d.inputs.ProbeFailures)))
+}
+
+// applyPodBudget reports decision to shared pod budget and holds scale up when budget doesn't grant it
+func (s *Scaler) applyPodBudget(d decision) decision {
+	if s.podBudget == nil {
+		return d
+	}
+
+	granted := s.podBudget.Acquire(s.deploymentName, d.current, d.desired)
+
+	if d.value != scaleUp {
+		return d
+	}
+
+	if granted < d.target && granted > d.current {
+		zap.S().With("deployment", s.deploymentName).Warnf("scale up limited by pod budget to %d replicas", granted)
+		d.target = granted
+		return d.gate(events.GatePodBudget, true, fmt.Sprintf("limited to %d replicas", granted))
+	}
+
+	if granted < d.target {
+		detail := fmt.Sprintf("%d/%d pods in use", s.podBudget.InUse(), s.podBudget.Budget())
+		zap.S().With("deployment", s.deploymentName).Warnf("scale up held, pod budget exhausted (%s)", detail)
+		return d.gate(events.GatePodBudget, false, detail).hold(events.ReasonPodBudgetExhausted)
+	}
+
+	return d.gate(events.GatePodBudget, true, "")
+}
+
+// applyCapacityCheck holds scale up when namespace quota has no headroom for new pods or pods can't be scheduled
+func (s *Scaler) applyCapacityCheck(ctx context.Context, d decision) decision {
+	if d.value != scaleUp {
+		return d
+	}
+
+	blocker, err := s.k8sService.CheckScaleUpCapacity(ctx, s.deployment, d.target-d.current)
+	if err != nil {
+		zap.S().With("deployment", s.deploymentName).With("error", err).Warn("failed to check capacity, proceeding with scale up")
+		return d.gate(events.GateCapacity, true, fmt.Sprintf("check failed: %v", err))
+	}
+
+	if blocker != "" {
+		zap.S().With("deployment", s.deploymentName).Warnf("scale up held: %s", blocker)
+		return d.gate(events.GateCapacity, false, blocker).hold(blocker)
+	}
+
+	return d.gate(events.GateCapacity, true, "")
+}
+
 func (s *Scaler) refreshDeployment(ctx context.Context) error {
 	scalerLogger := zap.S().With("deployment", s.deploymentName)
 	scalerLogger.Debug("starting refreshing of deployment")
@@ -289,18 +612,33 @@ func (s *Scaler) isAutoscalerInCooldown(currentTime time.Time) bool {
 
 
 func (s *Scaler) buildEventData(decision decision, probeResult int) *events.ScalingEventData {
-	loadPercentage := float64(probeResult) / float64(s.scalerConfig.Threshold) * 100
+	var loadPercentage float64
+	if s.scalerConfig.Threshold > 0 {
+		loadPercentage = float64(probeResult) / float64(s.scalerConfig.Threshold) * 100
+	}
 	
 	var scalingDirection, scalingReason string
-	switch decision.value {
-	case scaleUp:
+	targetReplicas := decision.target
+
+	switch {
+	case decision.heldBy != "":
+		scalingDirection = "up"
+		scalingReason = decision.heldBy
+		targetReplicas = decision.desired
+	case decision.scheduledBy != "":
+		scalingDirection = "up"
+		if decision.value == scaleDown {
+			scalingDirection = "down"
+		}
+		scalingReason = "scheduled"
+	case decision.value == scaleUp:
 		scalingDirection = "up"
 		if decision.target == s.scalerConfig.ApplicableLimits().MaximumNumberOfPods {
 			scalingReason = "at_max_limit"
 		} else {
 			scalingReason = "high_load"
 		}
-	case scaleDown:
+	case decision.value == scaleDown:
 		scalingDirection = "down"
 		if decision.target == s.scalerConfig.ApplicableLimits().MinimumNumberOfPods {
 			scalingReason = "at_min_limit"
@@ -311,7 +649,7 @@ func (s *Scaler) buildEventData(decision decision, probeResult int) *events.Scal
 	
 	eventData := &events.ScalingEventData{
 		CurrentReplicas:  decision.current,
-		TargetReplicas:   decision.target,
+		TargetReplicas:   targetReplicas,
 		ProbeValue:       probeResult,
 		Threshold:        s.scalerConfig.Threshold,
 		LoadPercentage:   loadPercentage,
@@ -320,6 +658,8 @@ func (s *Scaler) buildEventData(decision decision, probeResult int) *events.Scal
 		ScalingDirection: scalingDirection,
 		ProbeType:        s.probe.Kind(),
 		ScalingReason:    scalingReason,
+		Held:             decision.heldBy != "",
+		Explanation:      decision.record(s.deploymentName),
 		DeploymentName:   s.deployment.Name,
 		Namespace:        s.deployment.Namespace,
 		Environment:      s.globalConfig.Environment,
@@ -332,6 +672,15 @@ func (s *Scaler) buildEventData(decision decision, probeResult int) *events.Scal
 
 func ParseRawScalerConfig(rawConfig string) (Config, error) {
 	scalerConfig := NewScalerConfigWithDefaults()
-	err := yaml.Unmarshal([]byte(rawConfig), &scalerConfig)
-	return scalerConfig, err
+	if err := yaml.Unmarshal([]byte(rawConfig), &scalerConfig); err != nil {
+		return scalerConfig, err
+	}
+
+	for _, action := range scalerConfig.ScheduledActions {
+		if err := action.parse(); err != nil {
+			return scalerConfig, err
+		}
+	}
+
+	return scalerConfig, nil
 }
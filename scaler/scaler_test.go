@@ -14,9 +14,11 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/AirHelp/autoscaler/budget"
 	"github.com/AirHelp/autoscaler/config"
 	"github.com/AirHelp/autoscaler/notification"
 	notificationMock "github.com/AirHelp/autoscaler/notification/mock"
+	"github.com/AirHelp/autoscaler/probe/linked"
 	probeMock "github.com/AirHelp/autoscaler/probe/mock"
 	sqsProbe "github.com/AirHelp/autoscaler/probe/sqs"
 	scalerMock "github.com/AirHelp/autoscaler/scaler/mock"
@@ -28,6 +30,30 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+type trackerStub map[string]time.Time
+
+func (t trackerStub) LastReplicasChangeAt(deploymentName string) time.Time {
+	return t[deploymentName]
+}
+
+type partialProbeStub struct {
+	*probeMock.MockProbe
+	failures map[string]string
+}
+
+func (p partialProbeStub) Failures() map[string]string {
+	return p.failures
+}
+
+type latencyProbeStub struct {
+	*probeMock.MockProbe
+	breach string
+}
+
+func (p latencyProbeStub) LatencyBreach() string {
+	return p.breach
+}
+
 var _ = Describe("Scaler", func() {
 	var (
 		mockCtrl       *gomock.Controller
@@ -139,6 +165,54 @@ var _ = Describe("Scaler", func() {
 			Expect(sc.globalConfig).To(Equal(globalConfig))
 		})
 
+		It("When Redis stream probe requested it properly creates Redis stream based scaler", func() {
+			server := miniredis.RunT(GinkgoT())
+
+			k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+			input.RawYamlConfig = strings.Replace(testdata.LoadFixture("autoscaler-config-redis-stream.yaml"), "localhost:6379", server.Addr(), 1)
+
+			sc, err := New(input)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sc.probe.Kind()).To(Equal("redis_stream"))
+		})
+
+		It("When Sidekiq probe requested it properly creates Sidekiq based scaler", func() {
+			server := miniredis.RunT(GinkgoT())
+
+			k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+			input.RawYamlConfig = strings.Replace(testdata.LoadFixture("autoscaler-config-sidekiq.yaml"), "localhost:6379", server.Addr(), 1)
+
+			sc, err := New(input)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sc.probe.Kind()).To(Equal("sidekiq"))
+		})
+
+		It("When BullMQ probe requested it properly creates BullMQ based scaler", func() {
+			server := miniredis.RunT(GinkgoT())
+
+			k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+			input.RawYamlConfig = strings.Replace(testdata.LoadFixture("autoscaler-config-bullmq.yaml"), "localhost:6379", server.Addr(), 1)
+
+			sc, err := New(input)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sc.probe.Kind()).To(Equal("bullmq"))
+		})
+
+		It("When Celery probe requested it properly creates Celery based scaler", func() {
+			server := miniredis.RunT(GinkgoT())
+
+			k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+			input.RawYamlConfig = strings.Replace(testdata.LoadFixture("autoscaler-config-celery.yaml"), "localhost:6379", server.Addr(), 1)
+
+			sc, err := New(input)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sc.probe.Kind()).To(Equal("celery"))
+		})
+
 		It("When Nginx probe requested it properly creates Nginx based scaler", func() {
 			k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
 			input.RawYamlConfig = testdata.LoadFixture("autoscaler-config-nginx.yaml")
@@ -154,6 +228,25 @@ var _ = Describe("Scaler", func() {
 			Expect(sc.globalConfig).To(Equal(globalConfig))
 		})
 
+		It("When linked probe requested it properly creates linked scaler", func() {
+			k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+			input.RawYamlConfig = testdata.LoadFixture("autoscaler-config-linked.yaml")
+
+			sc, err := New(input)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sc.probe.Kind()).To(Equal("linked"))
+		})
+
+		It("When pod deletion cost has no endpoint it returns error", func() {
+			k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+			input.RawYamlConfig = testdata.LoadFixture("autoscaler-config-nginx.yaml") + "pod_deletion_cost:\n  port: 8080\n"
+
+			_, err := New(input)
+
+			Expect(err).To(Equal(ErrDeletionCostEndpointNotSpecified))
+		})
+
 		It("When fetching deployment fails it returns error", func() {
 			k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&appsv1.Deployment{}, errors.New("Failed to fetch deployment \"test-deployment\""))
 
@@ -195,6 +288,20 @@ var _ = Describe("Scaler", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(Equal(ErrProbeNotSpecified))
 		})
+
+		It("When probe can't be created it doesn't register in pod budget", func() {
+			input.RawYamlConfig = testdata.LoadFixture("autoscaler-config-without-probe.yaml")
+			input.PodBudget = budget.New(10)
+			Expect(input.PodBudget.Register("other-deployment", 0, 0, 2)).To(Succeed())
+
+			k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+
+			_, err := New(input)
+
+			Expect(err).To(Equal(ErrProbeNotSpecified))
+			Expect(input.PodBudget.Acquire(deploymentName, 0, 100)).To(Equal(100), "unregistered deployment is not limited")
+			Expect(input.PodBudget.InUse()).To(Equal(2))
+		})
 	})
 
 	Describe("Scaler receiver", func() {
@@ -267,6 +374,7 @@ var _ = Describe("Scaler", func() {
 					probeInstanceMock.EXPECT().Check(ctx).Return(500, nil)
 					probeInstanceMock.EXPECT().Kind().Return("sqs").AnyTimes()
 					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+					k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 1).Return("", nil)
 					k8sServiceMock.EXPECT().ScaleDeployment(ctx, &deployment, 5)
 					k8sServiceMock.EXPECT().CreateScalingEvent(ctx, &deployment, gomock.Any()).Do(func(ctx context.Context, deployment *appsv1.Deployment, eventData *events.ScalingEventData) {
 						Expect(eventData.ScalingDirection).To(Equal("up"))
@@ -274,11 +382,22 @@ var _ = Describe("Scaler", func() {
 						Expect(eventData.TargetReplicas).To(Equal(5))
 						Expect(eventData.ProbeType).To(Equal("sqs"))
 						Expect(eventData.ScalingReason).To(Equal("at_max_limit")) // Scaling to max limit (5)
+						Expect(eventData.Explanation.Action).To(Equal(events.ActionScaleUp))
 					})
 					notifierMock.EXPECT().Notify(ctx, gomock.Any()).DoAndReturn(
 						func(_ context.Context, payload notification.NotificationPayload) error {
 							Expect(payload.Decision).To(Equal("scale up deployment from 4 to 5 replicas"))
 							Expect(payload.DeploymentName).To(Equal("test-deployment"))
+							Expect(payload.Explanation.Inputs.ProbeValue).To(Equal(500))
+							Expect(payload.Explanation.Inputs.ProbeHistory).To(Equal([]int{500}))
+							Expect(payload.Explanation.Inputs.LimitsSource).To(Equal("default"))
+							Expect(payload.Explanation.Gates).To(Equal([]events.DecisionGate{
+								{Gate: events.GateRollout, Passed: true},
+								{Gate: events.GateCooldown, Passed: true},
+								{Gate: events.GateMaxLimit, Passed: true},
+								{Gate: events.GateCapacity, Passed: true},
+							}))
+							Expect(payload.Explanation.DesiredReplicas).To(Equal(5))
 							return nil
 						},
 					)
@@ -289,10 +408,11 @@ var _ = Describe("Scaler", func() {
 
 				It("Does not create events when EnableEvents is false", func() {
 					sc.scalerConfig.EnableEvents = false
-					
+
 					probeInstanceMock.EXPECT().Check(ctx).Return(500, nil)
 					probeInstanceMock.EXPECT().Kind().Return("sqs").AnyTimes()
 					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+					k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 1).Return("", nil)
 					k8sServiceMock.EXPECT().ScaleDeployment(ctx, &deployment, 5)
 					notifierMock.EXPECT().Notify(ctx, gomock.Any()).DoAndReturn(
 						func(_ context.Context, payload notification.NotificationPayload) error {
@@ -331,6 +451,70 @@ var _ = Describe("Scaler", func() {
 				})
 			})
 
+			Context("When persisting state is enabled", func() {
+				It("Persists state after decision", func() {
+					sc.scalerConfig.PersistState = true
+
+					probeInstanceMock.EXPECT().Check(ctx).Return(75, nil)
+					probeInstanceMock.EXPECT().Kind().Return("sqs").AnyTimes()
+					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+					k8sServiceMock.EXPECT().AnnotateDeployment(ctx, deploymentName, gomock.Any()).Do(func(_ context.Context, _ string, annotations map[string]string) {
+						Expect(annotations[stateAnnotation]).To(ContainSubstring(`"last_results":[75]`))
+					})
+
+					sc.perform(ctx)
+				})
+			})
+
+			Context("When probe knows pods busyness", func() {
+				It("Sets pod deletion costs before scaling down", func() {
+					sc.probe = costProbeStub{MockProbe: probeInstanceMock, costs: map[string]int{"pod1": 10}}
+					sc.scalerConfig.EnableEvents = false
+					sc.notifiers = nil
+
+					probeInstanceMock.EXPECT().Check(ctx).Return(0, nil)
+					probeInstanceMock.EXPECT().Kind().Return("nginx").AnyTimes()
+					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+					gomock.InOrder(
+						k8sServiceMock.EXPECT().AnnotatePod(ctx, "pod1", map[string]string{podDeletionCostAnnotation: "10"}),
+						k8sServiceMock.EXPECT().ScaleDeployment(ctx, &deployment, 3),
+					)
+
+					sc.perform(ctx)
+				})
+			})
+
+			Context("When probe result is partial", func() {
+				BeforeEach(func() {
+					sc.probe = partialProbeStub{MockProbe: probeInstanceMock, failures: map[string]string{"q1": "access denied"}}
+					probeInstanceMock.EXPECT().Kind().Return("sqs").AnyTimes()
+					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+				})
+
+				It("Does not scale down", func() {
+					probeInstanceMock.EXPECT().Check(ctx).Return(0, nil)
+
+					sc.perform(ctx)
+
+					Expect(sc.lastActionAt).To(BeZero())
+				})
+
+				It("Scales up", func() {
+					probeInstanceMock.EXPECT().Check(ctx).Return(500, nil)
+					k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 1).Return("", nil)
+					k8sServiceMock.EXPECT().ScaleDeployment(ctx, &deployment, 5)
+					k8sServiceMock.EXPECT().CreateScalingEvent(ctx, &deployment, gomock.Any())
+					notifierMock.EXPECT().Notify(ctx, gomock.Any()).DoAndReturn(
+						func(_ context.Context, payload notification.NotificationPayload) error {
+							Expect(payload.Explanation.Inputs.ProbeFailures).To(Equal(map[string]string{"q1": "access denied"}))
+							return nil
+						},
+					)
+
+					sc.perform(ctx)
+				})
+			})
+
 			Context("When deployment is not in full ready state", func() {
 				It("Does not make changes", func() {
 					probeInstanceMock.EXPECT().Check(ctx).Return(666, nil)
@@ -338,8 +522,55 @@ var _ = Describe("Scaler", func() {
 
 					deployment.Status.AvailableReplicas = int32(1)
 					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+					k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 0).Return("", nil)
+
+					sc.perform(ctx)
+				})
+
+				It("Does not make changes nor report hold when capacity check fails", func() {
+					probeInstanceMock.EXPECT().Check(ctx).Return(666, nil)
+					probeInstanceMock.EXPECT().Kind().Return("sqs").AnyTimes()
+
+					deployment.Status.AvailableReplicas = int32(1)
+					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+					k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 0).Return("", errors.New("forbidden"))
 
 					sc.perform(ctx)
+
+					Expect(sc.lastHeldBy).To(BeEmpty())
+				})
+
+				Context("Because its pods are pending unschedulable", func() {
+					BeforeEach(func() {
+						// one of 4 pods is Pending with PodScheduled condition false and Unschedulable reason
+						deployment.Status.AvailableReplicas = int32(3)
+
+						probeInstanceMock.EXPECT().Check(ctx).Return(666, nil).Times(2)
+						probeInstanceMock.EXPECT().Kind().Return("sqs").AnyTimes()
+						k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil).Times(2)
+						k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 0).Return(events.ReasonPodsUnschedulable, nil).Times(2)
+					})
+
+					It("Holds scale up and creates warning event with the blocker once while hold lasts", func() {
+						k8sServiceMock.EXPECT().CreateScalingEvent(ctx, &deployment, gomock.Any()).Do(func(ctx context.Context, deployment *appsv1.Deployment, eventData *events.ScalingEventData) {
+							Expect(eventData.Held).To(BeTrue())
+							Expect(eventData.ScalingDirection).To(Equal("up"))
+							Expect(eventData.ScalingReason).To(Equal(events.ReasonPodsUnschedulable))
+							Expect(eventData.Explanation.HeldBy).To(Equal(events.ReasonPodsUnschedulable))
+						})
+
+						sc.perform(ctx)
+						sc.perform(ctx)
+
+						Expect(sc.lastHeldBy).To(Equal(events.ReasonPodsUnschedulable))
+					})
+
+					It("Creates event again at the next check when creating it failed", func() {
+						k8sServiceMock.EXPECT().CreateScalingEvent(ctx, &deployment, gomock.Any()).Return(errors.New("forbidden")).Times(2)
+
+						sc.perform(ctx)
+						sc.perform(ctx)
+					})
 				})
 			})
 
@@ -355,6 +586,25 @@ var _ = Describe("Scaler", func() {
 					sc.perform(ctx)
 				})
 
+				Context("But deployment is linked to other deployment", func() {
+					It("Does not apply cooldown period and follows source deployment", func() {
+						linkedProbe, err := linked.New(&linked.Config{Deployment: "claims-worker", Ratio: 0.25}, k8sServiceMock)
+						Expect(err).ToNot(HaveOccurred())
+						sc.probe = linkedProbe
+						sc.lastActionAt = time.Now().Add(-30 * time.Second)
+
+						sourceReplicas := int32(20)
+						k8sServiceMock.EXPECT().GetDeployment(ctx, "claims-worker").Return(&appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &sourceReplicas}}, nil)
+						k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+						k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 1).Return("", nil)
+						k8sServiceMock.EXPECT().ScaleDeployment(ctx, &deployment, 5)
+						k8sServiceMock.EXPECT().CreateScalingEvent(ctx, &deployment, gomock.Any())
+						notifierMock.EXPECT().Notify(ctx, gomock.Any()).Return(nil)
+
+						sc.perform(ctx)
+					})
+				})
+
 				Context("But deployment is scaled down to 0", func() {
 					It("Does not apply cooldown period and makes a scaleUp decision", func() {
 						probeInstanceMock.EXPECT().Check(ctx).Return(666, nil)
@@ -368,6 +618,7 @@ var _ = Describe("Scaler", func() {
 						deployment.Spec.Replicas = &zeroReplicas
 
 						k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+						k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 1).Return("", nil)
 						k8sServiceMock.EXPECT().ScaleDeployment(ctx, &deployment, 1)
 						k8sServiceMock.EXPECT().CreateScalingEvent(ctx, &deployment, gomock.Any()).Do(func(ctx context.Context, deployment *appsv1.Deployment, eventData *events.ScalingEventData) {
 							Expect(eventData.ScalingDirection).To(Equal("up"))
@@ -386,6 +637,202 @@ var _ = Describe("Scaler", func() {
 				})
 			})
 
+			Context("When namespace has no capacity for new pods", func() {
+				It("Holds scale up and creates warning event with the blocker", func() {
+					probeInstanceMock.EXPECT().Check(ctx).Return(500, nil)
+					probeInstanceMock.EXPECT().Kind().Return("sqs").AnyTimes()
+					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+					k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 1).Return(events.ReasonResourceQuotaExceeded, nil)
+					k8sServiceMock.EXPECT().CreateScalingEvent(ctx, &deployment, gomock.Any()).Do(func(ctx context.Context, deployment *appsv1.Deployment, eventData *events.ScalingEventData) {
+						Expect(eventData.Held).To(BeTrue())
+						Expect(eventData.ScalingDirection).To(Equal("up"))
+						Expect(eventData.ScalingReason).To(Equal(events.ReasonResourceQuotaExceeded))
+						Expect(eventData.CurrentReplicas).To(Equal(4))
+						Expect(eventData.TargetReplicas).To(Equal(5))
+					})
+
+					sc.perform(ctx)
+				})
+
+				It("Creates event only when reason of hold changes", func() {
+					probeInstanceMock.EXPECT().Check(ctx).Return(500, nil).Times(4)
+					probeInstanceMock.EXPECT().Kind().Return("sqs").AnyTimes()
+					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil).Times(4)
+
+					gomock.InOrder(
+						k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 1).Return(events.ReasonResourceQuotaExceeded, nil).Times(2),
+						k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 1).Return(events.ReasonPodsUnschedulable, nil).Times(2),
+					)
+
+					var reasons []string
+					k8sServiceMock.EXPECT().CreateScalingEvent(ctx, &deployment, gomock.Any()).Do(func(ctx context.Context, deployment *appsv1.Deployment, eventData *events.ScalingEventData) {
+						reasons = append(reasons, eventData.ScalingReason)
+					}).Times(2)
+
+					for i := 0; i < 4; i++ {
+						sc.perform(ctx)
+					}
+
+					Expect(reasons).To(Equal([]string{events.ReasonResourceQuotaExceeded, events.ReasonPodsUnschedulable}))
+				})
+
+				It("Proceeds with scale up when capacity check fails", func() {
+					probeInstanceMock.EXPECT().Check(ctx).Return(500, nil)
+					probeInstanceMock.EXPECT().Kind().Return("sqs").AnyTimes()
+					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+					k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 1).Return("", errors.New("forbidden"))
+					k8sServiceMock.EXPECT().ScaleDeployment(ctx, &deployment, 5)
+					k8sServiceMock.EXPECT().CreateScalingEvent(ctx, &deployment, gomock.Any())
+					notifierMock.EXPECT().Notify(ctx, gomock.Any()).Return(nil)
+
+					sc.perform(ctx)
+				})
+			})
+
+			Context("When pod budget is exhausted", func() {
+				It("Holds scale up", func() {
+					probeInstanceMock.EXPECT().Check(ctx).Return(500, nil)
+					probeInstanceMock.EXPECT().Kind().Return("sqs").AnyTimes()
+					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+
+					k8sServiceMock.EXPECT().CreateScalingEvent(ctx, &deployment, gomock.Any()).Do(func(ctx context.Context, deployment *appsv1.Deployment, eventData *events.ScalingEventData) {
+						Expect(eventData.Held).To(BeTrue())
+						Expect(eventData.ScalingReason).To(Equal(events.ReasonPodBudgetExhausted))
+					})
+
+					sc.podBudget = budget.New(6)
+					Expect(sc.podBudget.Register(deploymentName, 0, 0, 4)).To(Succeed())
+					Expect(sc.podBudget.Register("other-deployment", 0, 0, 2)).To(Succeed())
+
+					sc.perform(ctx)
+					Expect(sc.lastTenResults).To(Equal([]int{500}))
+				})
+
+				It("Scales up when budget grants it", func() {
+					probeInstanceMock.EXPECT().Check(ctx).Return(500, nil)
+					probeInstanceMock.EXPECT().Kind().Return("sqs").AnyTimes()
+					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+					k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 1).Return("", nil)
+					k8sServiceMock.EXPECT().ScaleDeployment(ctx, &deployment, 5)
+					k8sServiceMock.EXPECT().CreateScalingEvent(ctx, &deployment, gomock.Any())
+					notifierMock.EXPECT().Notify(ctx, gomock.Any()).Return(nil)
+
+					sc.podBudget = budget.New(7)
+					Expect(sc.podBudget.Register(deploymentName, 0, 0, 4)).To(Succeed())
+					Expect(sc.podBudget.Register("other-deployment", 0, 0, 2)).To(Succeed())
+
+					sc.perform(ctx)
+				})
+
+				It("Scales up to pods left in budget when it grants only part of scale up", func() {
+					// linked deployment jumps straight to derived replicas, so budget can grant part of scale up
+					linkedProbe, err := linked.New(&linked.Config{Deployment: "claims-worker", Ratio: 0.25}, k8sServiceMock)
+					Expect(err).ToNot(HaveOccurred())
+					sc.probe = linkedProbe
+					sc.scalerConfig.MaximumNumberOfPods = 10
+
+					sourceReplicas := int32(40)
+					k8sServiceMock.EXPECT().GetDeployment(ctx, "claims-worker").Return(&appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &sourceReplicas}}, nil)
+					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+					k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 1).Return("", nil)
+					k8sServiceMock.EXPECT().ScaleDeployment(ctx, &deployment, 5)
+					k8sServiceMock.EXPECT().CreateScalingEvent(ctx, &deployment, gomock.Any()).Do(func(ctx context.Context, deployment *appsv1.Deployment, eventData *events.ScalingEventData) {
+						Expect(eventData.Held).To(BeFalse())
+						Expect(eventData.TargetReplicas).To(Equal(5))
+					})
+					notifierMock.EXPECT().Notify(ctx, gomock.Any()).DoAndReturn(
+						func(_ context.Context, payload notification.NotificationPayload) error {
+							Expect(payload.Decision).To(Equal("scale up deployment from 4 to 5 replicas"))
+							Expect(payload.Explanation.DesiredReplicas).To(Equal(10))
+							Expect(payload.Explanation.Gates).To(ContainElement(events.DecisionGate{Gate: events.GatePodBudget, Passed: true, Detail: "limited to 5 replicas"}))
+							return nil
+						},
+					)
+
+					sc.podBudget = budget.New(7)
+					Expect(sc.podBudget.Register(deploymentName, 0, 0, 4)).To(Succeed())
+					Expect(sc.podBudget.Register("other-deployment", 0, 0, 2)).To(Succeed())
+
+					sc.perform(ctx)
+				})
+			})
+
+			Context("When scheduled action is due", func() {
+				BeforeEach(func() {
+					action := &ScheduledAction{Name: "pre-warm", At: "07:45", Replicas: 8}
+					Expect(action.parse()).To(Succeed())
+					sc.scalerConfig.ScheduledActions = []*ScheduledAction{action}
+					sc.scalerConfig.CheckInterval = time.Minute
+					sc.lastScheduleCheckAt = time.Date(2020, 12, 14, 7, 44, 30, 0, time.UTC)
+
+					now = func() time.Time { return time.Date(2020, 12, 14, 7, 45, 30, 0, time.UTC) }
+				})
+
+				AfterEach(func() {
+					now = time.Now
+				})
+
+				It("Sets exact replicas ignoring cooldown and limits", func() {
+					sc.lastActionAt = now().Add(-30 * time.Second)
+
+					probeInstanceMock.EXPECT().Kind().Return("sqs").AnyTimes()
+					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil)
+					k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 4).Return("", nil)
+					k8sServiceMock.EXPECT().ScaleDeployment(ctx, &deployment, 8)
+					k8sServiceMock.EXPECT().CreateScalingEvent(ctx, &deployment, gomock.Any()).Do(func(ctx context.Context, deployment *appsv1.Deployment, eventData *events.ScalingEventData) {
+						Expect(eventData.ScalingDirection).To(Equal("up"))
+						Expect(eventData.TargetReplicas).To(Equal(8))
+						Expect(eventData.ScalingReason).To(Equal("scheduled"))
+					})
+					notifierMock.EXPECT().Notify(ctx, gomock.Any()).DoAndReturn(
+						func(_ context.Context, payload notification.NotificationPayload) error {
+							Expect(payload.Decision).To(Equal("scale up deployment from 4 to 8 replicas (scheduled action `pre-warm`)"))
+							return nil
+						},
+					)
+
+					sc.perform(ctx)
+					Expect(sc.lastActionAt).To(Equal(now()))
+					Expect(sc.lastTenResults).To(BeEmpty())
+				})
+
+				It("Resumes regular autoscaling on the next check", func() {
+					probeInstanceMock.EXPECT().Kind().Return("sqs").AnyTimes()
+					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil).Times(2)
+					k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 4).Return("", nil)
+					k8sServiceMock.EXPECT().ScaleDeployment(ctx, &deployment, 8)
+					k8sServiceMock.EXPECT().CreateScalingEvent(ctx, &deployment, gomock.Any())
+					notifierMock.EXPECT().Notify(ctx, gomock.Any()).Return(nil)
+
+					sc.perform(ctx)
+
+					probeInstanceMock.EXPECT().Check(ctx).Return(0, nil)
+					now = func() time.Time { return time.Date(2020, 12, 14, 7, 46, 30, 0, time.UTC) }
+
+					sc.perform(ctx)
+					Expect(sc.lastTenResults).To(Equal([]int{0}))
+				})
+
+				It("Retries action on the next check when scaling failed", func() {
+					probeInstanceMock.EXPECT().Kind().Return("sqs").AnyTimes()
+					k8sServiceMock.EXPECT().GetDeployment(ctx, deploymentName).Return(&deployment, nil).Times(2)
+					k8sServiceMock.EXPECT().CheckScaleUpCapacity(ctx, &deployment, 4).Return("", nil).Times(2)
+					gomock.InOrder(
+						k8sServiceMock.EXPECT().ScaleDeployment(ctx, &deployment, 8).Return(nil, errors.New("conflict")),
+						k8sServiceMock.EXPECT().ScaleDeployment(ctx, &deployment, 8),
+					)
+					k8sServiceMock.EXPECT().CreateScalingEvent(ctx, &deployment, gomock.Any()).Times(2)
+					notifierMock.EXPECT().Notify(ctx, gomock.Any()).Return(nil).Times(2)
+
+					sc.perform(ctx)
+
+					now = func() time.Time { return time.Date(2020, 12, 14, 7, 46, 30, 0, time.UTC) }
+
+					sc.perform(ctx)
+					Expect(sc.lastScheduleCheckAt).To(Equal(now()))
+				})
+			})
+
 			Context("When probe fails", func() {
 				It("Does not make changes", func() {
 					probeInstanceMock.EXPECT().Check(ctx).Return(0, errors.New("random error"))
@@ -409,6 +856,7 @@ var _ = Describe("Scaler", func() {
 
 			BeforeEach(func() {
 				probeInstanceMock = probeMock.NewMockProbe(mockCtrl)
+				probeInstanceMock.EXPECT().Kind().Return("sqs").AnyTimes()
 
 				currentReplicas = int32(4)
 				deployment = appsv1.Deployment{
@@ -504,6 +952,10 @@ var _ = Describe("Scaler", func() {
 						Expect(res.value).To(Equal(remain))
 						Expect(res.current).To(Equal(1))
 						Expect(res.target).To(Equal(1))
+						Expect(res.gates).To(Equal([]events.DecisionGate{
+							{Gate: events.GateMinLimit, Passed: true},
+							{Gate: events.GateConsecutiveZeros, Passed: false, Detail: "5 consecutive zero readouts required"},
+						}))
 					})
 				})
 
@@ -519,6 +971,68 @@ var _ = Describe("Scaler", func() {
 				})
 			})
 
+			Context("When work waits longer than allowed", func() {
+				BeforeEach(func() {
+					sc.probe = latencyProbeStub{MockProbe: probeInstanceMock, breach: "oldest message of queue `emails` is 20m0s old, over 15m0s"}
+				})
+
+				It("Decides to scale up even when probe result is below threshold", func() {
+					res := sc.calculateDecision(10)
+
+					Expect(res.value).To(Equal(scaleUp))
+					Expect(res.target).To(Equal(5))
+					Expect(res.inputs.LatencyBreach).To(Equal("oldest message of queue `emails` is 20m0s old, over 15m0s"))
+				})
+
+				It("Decides to remain when maximum number of pods is reached", func() {
+					sc.scalerConfig.MaximumNumberOfPods = 4
+
+					res := sc.calculateDecision(10)
+
+					Expect(res.value).To(Equal(remain))
+				})
+			})
+
+			Context("When deployment is linked to other deployment", func() {
+				BeforeEach(func() {
+					linkedProbe, err := linked.New(&linked.Config{Deployment: "claims-worker", Ratio: 0.25}, k8sServiceMock)
+					Expect(err).ToNot(HaveOccurred())
+					sc.probe = linkedProbe
+				})
+
+				It("Scales up directly to derived replicas", func() {
+					res := sc.calculateDecision(20)
+
+					Expect(res.value).To(Equal(scaleUp))
+					Expect(res.current).To(Equal(4))
+					Expect(res.target).To(Equal(5))
+				})
+
+				It("Scales down directly to derived replicas", func() {
+					res := sc.calculateDecision(4)
+
+					Expect(res.value).To(Equal(scaleDown))
+					Expect(res.current).To(Equal(4))
+					Expect(res.target).To(Equal(1))
+				})
+
+				It("Respects own limits", func() {
+					sc.scalerConfig.MinimumNumberOfPods = 2
+
+					res := sc.calculateDecision(0)
+
+					Expect(res.value).To(Equal(scaleDown))
+					Expect(res.target).To(Equal(2))
+				})
+
+				It("Remains when derived replicas are same", func() {
+					res := sc.calculateDecision(16)
+
+					Expect(res.value).To(Equal(remain))
+					Expect(res.target).To(Equal(4))
+				})
+			})
+
 			Context("When scaler config is overwritten by hourly config", func() {
 				BeforeEach(func() {
 					scalerConfig = Config{
@@ -533,6 +1047,7 @@ var _ = Describe("Scaler", func() {
 									MinimumNumberOfPods: 1,
 									MaximumNumberOfPods: 3,
 								},
+								Name:      "business-hours",
 								StartHour: 9,
 								EndHour:   17,
 							},
@@ -572,12 +1087,146 @@ var _ = Describe("Scaler", func() {
 						Expect(res.value).To(Equal(remain))
 						Expect(res.current).To(Equal(1))
 						Expect(res.target).To(Equal(1))
+						Expect(res.inputs.MinPods).To(Equal(1))
+						Expect(res.inputs.LimitsSource).To(Equal("business-hours"))
+						Expect(res.gates).To(Equal([]events.DecisionGate{
+							{Gate: events.GateMinLimit, Passed: false, Detail: "minimum of 1 pods set by business-hours limits"},
+						}))
 					})
 				})
 			})
 
 		})
 
+		Describe("newDecision()", func() {
+			It("Records inputs of decision", func() {
+				replicas := int32(3)
+				probeInstanceMock := probeMock.NewMockProbe(mockCtrl)
+				probeInstanceMock.EXPECT().Kind().Return("sqs")
+
+				sc := Scaler{
+					deploymentName: deploymentName,
+					deployment: &appsv1.Deployment{
+						Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+						Status: appsv1.DeploymentStatus{Replicas: 3, AvailableReplicas: 2},
+					},
+					probe:          probeInstanceMock,
+					lastTenResults: []int{10, 40},
+					lastActionAt:   time.Now().Add(-time.Minute),
+					scalerConfig: Config{
+						MinMaxConfig:   MinMaxConfig{MinimumNumberOfPods: 1, MaximumNumberOfPods: 5},
+						Threshold:      20,
+						CooldownPeriod: 5 * time.Minute,
+					},
+				}
+
+				d := sc.newDecision(40)
+
+				Expect(d.value).To(Equal(remain))
+				Expect(d.target).To(Equal(3))
+				Expect(d.inputs.CooldownRemainingSeconds).To(BeNumerically("~", 240, 1))
+
+				d.inputs.CooldownRemainingSeconds = 0
+				Expect(d.inputs).To(Equal(events.DecisionInputs{
+					ProbeType:         "sqs",
+					ProbeValue:        40,
+					ProbeHistory:      []int{10, 40},
+					Threshold:         20,
+					CurrentReplicas:   3,
+					AvailableReplicas: 2,
+					MinPods:           1,
+					MaxPods:           5,
+					LimitsSource:      "default",
+				}))
+			})
+		})
+
+		Describe("Start()", func() {
+			var (
+				k8sServiceMock *scalerMock.MockK8SClient
+				stoppedCtx     context.Context
+			)
+
+			BeforeEach(func() {
+				k8sServiceMock = scalerMock.NewMockK8SClient(mockCtrl)
+
+				var cancel context.CancelFunc
+				stoppedCtx, cancel = context.WithCancel(context.Background())
+				cancel()
+			})
+
+			It("Restores persisted state written meanwhile by previous leader", func() {
+				sc := Scaler{
+					deploymentName: deploymentName,
+					deployment:     &appsv1.Deployment{},
+					k8sService:     k8sServiceMock,
+					lastTenResults: []int{1},
+					scalerConfig:   Config{CheckInterval: time.Hour, PersistState: true},
+				}
+
+				k8sServiceMock.EXPECT().GetDeployment(stoppedCtx, deploymentName).Return(&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							stateAnnotation: `{"version":1,"last_results":[5,0,0],"last_action_at":"2020-12-14T15:00:00Z"}`,
+						},
+					},
+				}, nil)
+
+				sc.Start(stoppedCtx)
+
+				Expect(sc.lastTenResults).To(Equal([]int{5, 0, 0}))
+				Expect(sc.lastActionAt).To(Equal(time.Date(2020, 12, 14, 15, 0, 0, 0, time.UTC)))
+			})
+
+			It("Doesn't touch deployment when state isn't persisted", func() {
+				sc := Scaler{
+					deploymentName: deploymentName,
+					k8sService:     k8sServiceMock,
+					lastTenResults: []int{1},
+					scalerConfig:   Config{CheckInterval: time.Hour},
+				}
+
+				sc.Start(stoppedCtx)
+
+				Expect(sc.lastTenResults).To(Equal([]int{1}))
+			})
+		})
+
+		Describe("restoreCooldown()", func() {
+			It("Takes over replicas change made by other instance", func() {
+				changedAt := time.Now().Add(-time.Minute)
+				sc := Scaler{
+					deploymentName: deploymentName,
+					tracker:        trackerStub{deploymentName: changedAt},
+				}
+
+				sc.restoreCooldown()
+
+				Expect(sc.lastActionAt).To(Equal(changedAt))
+			})
+
+			It("Keeps own last action when it is more recent", func() {
+				lastActionAt := time.Now().Add(-time.Minute)
+				sc := Scaler{
+					deploymentName: deploymentName,
+					lastActionAt:   lastActionAt,
+					tracker:        trackerStub{deploymentName: lastActionAt.Add(-time.Hour)},
+				}
+
+				sc.restoreCooldown()
+
+				Expect(sc.lastActionAt).To(Equal(lastActionAt))
+			})
+
+			It("Does nothing without tracker", func() {
+				sc := Scaler{deploymentName: deploymentName}
+
+				sc.restoreCooldown()
+
+				Expect(sc.lastActionAt).To(BeZero())
+			})
+		})
+
 		Describe("refreshDeployment", func() {
 			var (
 				probeInstanceMock *probeMock.MockProbe
@@ -0,0 +1,149 @@
+package scaler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ScheduledAction sets exact number of replicas at given time, e.g. to pre-warm deployment before expected traffic
+type ScheduledAction struct {
+	Name     string   `yaml:"name"`
+	At       string   `yaml:"at"`
+	Days     []string `yaml:"days"`
+	Replicas int      `yaml:"replicas"`
+
+	hour     int
+	minute   int
+	weekdays map[time.Weekday]bool
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func (sa *ScheduledAction) parse() error {
+	at, err := time.Parse("15:04", sa.At)
+	if err != nil {
+		return fmt.Errorf("invalid time `%v` of scheduled action `%v`, expected HH:MM", sa.At, sa.Name)
+	}
+
+	if sa.Replicas < 0 {
+		return fmt.Errorf("replicas of scheduled action `%v` cannot be negative", sa.Name)
+	}
+
+	sa.hour, sa.minute = at.Hour(), at.Minute()
+	sa.weekdays = map[time.Weekday]bool{}
+
+	for _, day := range sa.Days {
+		weekday, ok := weekdayNames[strings.ToLower(day)]
+		if !ok {
+			return fmt.Errorf("invalid day `%v` of scheduled action `%v`, expected one of mon, tue, wed, thu, fri, sat, sun", day, sa.Name)
+		}
+
+		sa.weekdays[weekday] = true
+	}
+
+	return nil
+}
+
+// lastOccurrence returns the most recent time not after t when the action should have run
+func (sa *ScheduledAction) lastOccurrence(t time.Time) time.Time {
+	year, month, day := t.Date()
+
+	for daysBack := 0; daysBack <= 7; daysBack++ {
+		occurrence := time.Date(year, month, day-daysBack, sa.hour, sa.minute, 0, 0, t.Location())
+
+		if occurrence.After(t) {
+			continue
+		}
+
+		if len(sa.weekdays) == 0 || sa.weekdays[occurrence.Weekday()] {
+			return occurrence
+		}
+	}
+
+	return time.Time{}
+}
+
+// scheduleCatchUpIntervals is how many check intervals back action is still applied, so action which failed is
+// retried on the next check while actions missed long ago (eg. when instance wasn't leading) are skipped
+const scheduleCatchUpIntervals = 2
+
+// dueScheduledAction returns the action which time passed since the last completed check, the latest one when there are many
+func (s *Scaler) dueScheduledAction(currentTime time.Time) *ScheduledAction {
+	if len(s.scalerConfig.ScheduledActions) == 0 {
+		return nil
+	}
+
+	lastCheck := s.lastScheduleCheckAt
+	if oldest := currentTime.Add(-scheduleCatchUpIntervals * s.scalerConfig.CheckInterval); lastCheck.Before(oldest) {
+		lastCheck = oldest
+	}
+
+	var (
+		due   *ScheduledAction
+		dueAt time.Time
+	)
+
+	for _, action := range s.scalerConfig.ScheduledActions {
+		occurrence := action.lastOccurrence(currentTime)
+
+		if occurrence.After(lastCheck) && occurrence.After(dueAt) {
+			due, dueAt = action, occurrence
+		}
+	}
+
+	return due
+}
+
+// performScheduledAction sets replicas requested by the action regardless of cooldown and limits,
+// starting cooldown after which regular autoscaling resumes. Action is marked done only when it succeeded.
+func (s *Scaler) performScheduledAction(ctx context.Context, action *ScheduledAction, currentTime time.Time) {
+	scalerLogger := zap.S().With("deployment", s.deploymentName)
+	scalerLogger.Infof("running scheduled action `%v`", action.Name)
+
+	if err := s.refreshDeployment(ctx); err != nil {
+		scalerLogger.With("error", err).Warnf("failed to refresh deployment, skipping scheduled action: %v", err)
+		return
+	}
+
+	// probe isn't checked for scheduled action
+	d := s.newDecision(0)
+	d.desired = action.Replicas
+	d.scheduledBy = action.Name
+
+	switch {
+	case action.Replicas > d.current:
+		d.value = scaleUp
+		d.target = action.Replicas
+	case action.Replicas < d.current:
+		d.value = scaleDown
+		d.target = action.Replicas
+	}
+
+	d = s.applyGates(ctx, d, 0)
+	s.logDecision(d)
+
+	if d.value != remain {
+		if err := s.executeDecision(ctx, d, 0, currentTime); err != nil {
+			scalerLogger.Warnf("scheduled action `%v` failed, retrying on the next check", action.Name)
+			return
+		}
+
+		if s.scalerConfig.PersistState {
+			s.persistState(ctx)
+		}
+	}
+
+	s.lastScheduleCheckAt = currentTime
+}
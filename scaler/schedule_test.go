@@ -0,0 +1,107 @@
+package scaler
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ScheduledAction", func() {
+	// 2020-12-14 is Monday
+	monday := time.Date(2020, 12, 14, 7, 45, 0, 0, time.UTC)
+
+	Describe("parse()", func() {
+		It("Parses time and days", func() {
+			action := &ScheduledAction{Name: "pre-warm", At: "07:45", Days: []string{"mon", "FRI"}, Replicas: 6}
+
+			Expect(action.parse()).To(Succeed())
+			Expect(action.hour).To(Equal(7))
+			Expect(action.minute).To(Equal(45))
+			Expect(action.weekdays).To(Equal(map[time.Weekday]bool{time.Monday: true, time.Friday: true}))
+		})
+
+		DescribeTable("Returns error on invalid action",
+			func(action ScheduledAction) {
+				Expect(action.parse()).ToNot(Succeed())
+			},
+			Entry("When time is malformed", ScheduledAction{At: "7.45"}),
+			Entry("When time is out of range", ScheduledAction{At: "25:00"}),
+			Entry("When day is unknown", ScheduledAction{At: "07:45", Days: []string{"monday"}}),
+			Entry("When replicas are negative", ScheduledAction{At: "07:45", Replicas: -1}),
+		)
+	})
+
+	Describe("lastOccurrence()", func() {
+		DescribeTable("Finds the most recent occurrence",
+			func(days []string, t time.Time, expected time.Time) {
+				action := &ScheduledAction{At: "07:45", Days: days}
+				Expect(action.parse()).To(Succeed())
+
+				Expect(action.lastOccurrence(t)).To(Equal(expected))
+			},
+			Entry("When action time is now", nil, monday, monday),
+			Entry("When action time didn't come yet today", nil, monday.Add(-time.Minute), monday.AddDate(0, 0, -1)),
+			Entry("When action runs on weekdays only", []string{"mon", "tue", "wed", "thu", "fri"}, monday.Add(-time.Minute), monday.AddDate(0, 0, -3)),
+		)
+	})
+
+	Describe("Scaler.dueScheduledAction()", func() {
+		var sc Scaler
+
+		BeforeEach(func() {
+			preWarm := &ScheduledAction{Name: "pre-warm", At: "07:45", Days: []string{"mon", "tue", "wed", "thu", "fri"}, Replicas: 6}
+			night := &ScheduledAction{Name: "night", At: "22:00", Replicas: 1}
+			Expect(preWarm.parse()).To(Succeed())
+			Expect(night.parse()).To(Succeed())
+
+			sc = Scaler{
+				scalerConfig: Config{
+					CheckInterval:    time.Minute,
+					ScheduledActions: []*ScheduledAction{preWarm, night},
+				},
+			}
+		})
+
+		It("Returns action which time passed since previous check", func() {
+			sc.lastScheduleCheckAt = monday.Add(-30 * time.Second)
+
+			Expect(sc.dueScheduledAction(monday.Add(30 * time.Second)).Name).To(Equal("pre-warm"))
+		})
+
+		It("Returns action until check after its time is completed", func() {
+			sc.lastScheduleCheckAt = monday.Add(-30 * time.Second)
+
+			Expect(sc.dueScheduledAction(monday)).ToNot(BeNil())
+			Expect(sc.dueScheduledAction(monday.Add(time.Minute))).ToNot(BeNil())
+
+			sc.lastScheduleCheckAt = monday.Add(time.Minute)
+			Expect(sc.dueScheduledAction(monday.Add(2 * time.Minute))).To(BeNil())
+		})
+
+		It("Looks back two check intervals on the first check", func() {
+			Expect(sc.dueScheduledAction(monday.Add(119 * time.Second))).ToNot(BeNil())
+			Expect(sc.dueScheduledAction(monday.Add(2 * time.Minute))).To(BeNil())
+		})
+
+		It("Skips actions missed longer than two check intervals ago", func() {
+			sc.lastScheduleCheckAt = monday.Add(-time.Hour)
+
+			Expect(sc.dueScheduledAction(monday.Add(3 * time.Minute))).To(BeNil())
+		})
+
+		It("Doesn't return action on days it's not scheduled for", func() {
+			sunday := monday.AddDate(0, 0, -1)
+			sc.lastScheduleCheckAt = sunday.Add(-30 * time.Second)
+
+			Expect(sc.dueScheduledAction(sunday)).To(BeNil())
+		})
+
+		It("Returns the latest action when many passed", func() {
+			sc.scalerConfig.CheckInterval = 8 * time.Hour
+			sc.lastScheduleCheckAt = monday.Add(-time.Minute)
+
+			Expect(sc.dueScheduledAction(monday.Add(15 * time.Hour)).Name).To(Equal("night"))
+		})
+	})
+})
@@ -0,0 +1,111 @@
+package scaler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SeriesFormatCSV   = "csv"
+	SeriesFormatJSONL = "jsonl"
+)
+
+// ReadSeries reads probe series of (timestamp, value) pairs. Timestamp is either RFC3339 time or unix seconds.
+//
+// CSV has `timestamp,value` columns, header row is optional. JSONL has one `{"timestamp": ..., "value": ...}` object per line.
+func ReadSeries(r io.Reader, format string) ([]Sample, error) {
+	switch format {
+	case SeriesFormatCSV:
+		return readCSVSeries(r)
+	case SeriesFormatJSONL:
+		return readJSONLSeries(r)
+	default:
+		return nil, fmt.Errorf("unknown series format `%v`, expected %v or %v", format, SeriesFormatCSV, SeriesFormatJSONL)
+	}
+}
+
+func readCSVSeries(r io.Reader) ([]Sample, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	var series []Sample
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return series, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		sample, err := parseSample(record[0], record[1])
+		if err != nil {
+			if line == 1 {
+				// header row
+				continue
+			}
+
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		series = append(series, sample)
+	}
+}
+
+func readJSONLSeries(r io.Reader) ([]Sample, error) {
+	scanner := bufio.NewScanner(r)
+
+	var series []Sample
+
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var row struct {
+			Timestamp json.RawMessage `json:"timestamp"`
+			Value     json.Number     `json:"value"`
+		}
+
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		sample, err := parseSample(strings.Trim(string(row.Timestamp), `"`), row.Value.String())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		series = append(series, sample)
+	}
+
+	return series, scanner.Err()
+}
+
+func parseSample(rawTimestamp, rawValue string) (Sample, error) {
+	var (
+		sample Sample
+		err    error
+	)
+
+	if seconds, err := strconv.ParseInt(rawTimestamp, 10, 64); err == nil {
+		sample.Timestamp = time.Unix(seconds, 0).UTC()
+	} else if sample.Timestamp, err = time.Parse(time.RFC3339, rawTimestamp); err != nil {
+		return sample, fmt.Errorf("invalid timestamp `%v`", rawTimestamp)
+	}
+
+	if sample.Value, err = strconv.Atoi(rawValue); err != nil {
+		return sample, fmt.Errorf("invalid probe value `%v`", rawValue)
+	}
+
+	return sample, nil
+}
@@ -0,0 +1,52 @@
+package scaler
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadSeries()", func() {
+	expected := []Sample{
+		{Timestamp: time.Date(2024, 1, 8, 8, 0, 0, 0, time.UTC), Value: 10},
+		{Timestamp: time.Date(2024, 1, 8, 8, 1, 0, 0, time.UTC), Value: 25},
+	}
+
+	It("Reads CSV series with header", func() {
+		series, err := ReadSeries(strings.NewReader("timestamp,value\n2024-01-08T08:00:00Z,10\n1704700860, 25\n"), SeriesFormatCSV)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(series).To(Equal(expected))
+	})
+
+	It("Reads CSV series without header", func() {
+		series, err := ReadSeries(strings.NewReader("2024-01-08T08:00:00Z,10\n2024-01-08T08:01:00Z,25\n"), SeriesFormatCSV)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(series).To(Equal(expected))
+	})
+
+	It("Reads JSONL series", func() {
+		series, err := ReadSeries(strings.NewReader(`{"timestamp": "2024-01-08T08:00:00Z", "value": 10}
+
+{"timestamp": 1704700860, "value": 25}
+`), SeriesFormatJSONL)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(series).To(Equal(expected))
+	})
+
+	DescribeTable("Returns error on malformed series",
+		func(raw, format string) {
+			_, err := ReadSeries(strings.NewReader(raw), format)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("When CSV value isn't a number", "2024-01-08T08:00:00Z,10\n2024-01-08T08:01:00Z,many\n", SeriesFormatCSV),
+		Entry("When CSV has too many columns", "2024-01-08T08:00:00Z,10,1\n", SeriesFormatCSV),
+		Entry("When JSONL timestamp is malformed", `{"timestamp": "yesterday", "value": 10}`, SeriesFormatJSONL),
+		Entry("When JSONL line isn't JSON", "2024-01-08T08:00:00Z,10", SeriesFormatJSONL),
+		Entry("When format is unknown", "", "xml"),
+	)
+})
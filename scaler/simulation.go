@@ -0,0 +1,177 @@
+package scaler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/AirHelp/autoscaler/events"
+	"github.com/AirHelp/autoscaler/notification"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+const simulationKind = "simulation"
+
+var (
+	ErrEmptySeries                  = errors.New("probe series is empty")
+	ErrSimulationThreshold          = errors.New("threshold has to be greater than 0 to simulate scaling")
+	ErrSimulationCheckInterval      = errors.New("check interval has to be greater than 0 to simulate scaling")
+	ErrLinkedSimulationNotSupported = errors.New("linked deployments can't be simulated, simulate their source deployment instead")
+)
+
+// Sample is a single probe readout at given time
+type Sample struct {
+	Timestamp time.Time
+	Value     int
+}
+
+// SimulationStep is a change of replicas made during simulation
+type SimulationStep struct {
+	Time       time.Time
+	ProbeValue int
+	Replicas   int
+	Decision   string
+}
+
+type SimulationResult struct {
+	Timeline []SimulationStep
+	Actions  int
+
+	PodMinutes           float64
+	BacklogAboveCapacity time.Duration
+}
+
+// Simulate replays probe series through scaler decision logic on a virtual clock. Scaler checks the most recent
+// sample every check interval, pods are assumed to be ready right after scaling.
+//
+// Simulate stubs package clock, so it's not safe to run it concurrently with other scalers.
+func Simulate(config Config, series []Sample, initialReplicas int) (SimulationResult, error) {
+	var result SimulationResult
+
+	switch {
+	case len(series) == 0:
+		return result, ErrEmptySeries
+	case config.Linked != nil:
+		return result, ErrLinkedSimulationNotSupported
+	case config.Threshold <= 0:
+		return result, ErrSimulationThreshold
+	case config.CheckInterval <= 0:
+		return result, ErrSimulationCheckInterval
+	}
+
+	series = append([]Sample(nil), series...)
+	sort.SliceStable(series, func(i, j int) bool { return series[i].Timestamp.Before(series[j].Timestamp) })
+
+	config.EnableEvents = false
+	config.PersistState = false
+	config.DeletionCost = nil
+
+	sim := &simulator{}
+	sim.setReplicas(initialReplicas)
+
+	s := Scaler{
+		deploymentName: simulationKind,
+		deployment:     &sim.deployment,
+		scalerConfig:   config,
+		probe:          sim,
+		k8sService:     sim,
+		notifiers:      []notification.Notifier{sim},
+	}
+
+	defer func() { now = time.Now }()
+
+	ctx := context.Background()
+	start := series[0].Timestamp
+	result.Timeline = append(result.Timeline, SimulationStep{Time: start, ProbeValue: series[0].Value, Replicas: initialReplicas})
+
+	next := 0
+	for tick := start; !tick.After(series[len(series)-1].Timestamp); tick = tick.Add(config.CheckInterval) {
+		for next < len(series) && !series[next].Timestamp.After(tick) {
+			sim.value = series[next].Value
+			next++
+		}
+
+		now = func() time.Time { return tick }
+		s.perform(ctx)
+
+		replicas := int(*sim.deployment.Spec.Replicas)
+		result.PodMinutes += float64(replicas) * config.CheckInterval.Minutes()
+
+		if sim.value > replicas*config.Threshold {
+			result.BacklogAboveCapacity += config.CheckInterval
+		}
+	}
+
+	result.Timeline = append(result.Timeline, sim.steps...)
+	result.Actions = len(sim.steps)
+
+	return result, nil
+}
+
+// simulator stands for probe, Kubernetes and notifier of a simulated scaler
+type simulator struct {
+	deployment appsv1.Deployment
+	value      int
+	steps      []SimulationStep
+}
+
+func (sim *simulator) setReplicas(replicas int) {
+	r := int32(replicas)
+	sim.deployment.Spec.Replicas = &r
+	sim.deployment.Status.Replicas = r
+	sim.deployment.Status.AvailableReplicas = r
+}
+
+func (sim *simulator) Kind() string {
+	return simulationKind
+}
+
+func (sim *simulator) Check(context.Context) (int, error) {
+	return sim.value, nil
+}
+
+func (sim *simulator) Notify(_ context.Context, payload notification.NotificationPayload) error {
+	sim.steps = append(sim.steps, SimulationStep{
+		Time:       payload.ChangedAt,
+		ProbeValue: sim.value,
+		Replicas:   int(*sim.deployment.Spec.Replicas),
+		Decision:   payload.Decision,
+	})
+
+	return nil
+}
+
+func (sim *simulator) Alert(context.Context, notification.AlertPayload) error {
+	return nil
+}
+
+func (sim *simulator) GetDeployment(context.Context, string) (*appsv1.Deployment, error) {
+	return &sim.deployment, nil
+}
+
+func (sim *simulator) ScaleDeployment(_ context.Context, _ *appsv1.Deployment, replicas int) (*appsv1.Deployment, error) {
+	sim.setReplicas(replicas)
+	return &sim.deployment, nil
+}
+
+func (sim *simulator) CreateScalingEvent(context.Context, *appsv1.Deployment, *events.ScalingEventData) error {
+	return nil
+}
+
+func (sim *simulator) CheckScaleUpCapacity(context.Context, *appsv1.Deployment, int) (string, error) {
+	return "", nil
+}
+
+func (sim *simulator) AnnotateDeployment(context.Context, string, map[string]string) error {
+	return nil
+}
+
+func (sim *simulator) AnnotatePod(context.Context, string, map[string]string) error {
+	return nil
+}
+
+func (sim *simulator) GetPodsFromDeployment(context.Context, *appsv1.Deployment, map[string]string) (*v1.PodList, error) {
+	return &v1.PodList{}, nil
+}
@@ -0,0 +1,110 @@
+package scaler
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Simulate()", func() {
+	var (
+		config Config
+		start  time.Time
+	)
+
+	seriesOf := func(values ...int) []Sample {
+		series := make([]Sample, 0, len(values))
+		for i, value := range values {
+			series = append(series, Sample{Timestamp: start.Add(time.Duration(i) * time.Minute), Value: value})
+		}
+
+		return series
+	}
+
+	BeforeEach(func() {
+		start = time.Date(2024, 1, 8, 8, 0, 0, 0, time.UTC)
+		config = Config{
+			MinMaxConfig: MinMaxConfig{
+				MinimumNumberOfPods: 0,
+				MaximumNumberOfPods: 3,
+			},
+			CheckInterval:  time.Minute,
+			CooldownPeriod: 2 * time.Minute,
+			Threshold:      10,
+			EnableEvents:   true,
+			PersistState:   true,
+		}
+	})
+
+	It("Replays series applying cooldown and consecutive zeros rule", func() {
+		result, err := Simulate(config, seriesOf(30, 30, 30, 30, 30, 0, 0, 0, 0, 0, 0, 0, 0), 1)
+		Expect(err).ToNot(HaveOccurred())
+
+		var (
+			replicas []int
+			minutes  []float64
+		)
+		for _, step := range result.Timeline {
+			replicas = append(replicas, step.Replicas)
+			minutes = append(minutes, step.Time.Sub(start).Minutes())
+		}
+
+		Expect(replicas).To(Equal([]int{1, 2, 3, 2, 1, 0}))
+		Expect(minutes).To(Equal([]float64{0, 0, 2, 5, 7, 9}))
+		Expect(result.Timeline[1].Decision).To(Equal("scale up deployment from 1 to 2 replicas"))
+		Expect(result.Actions).To(Equal(5))
+	})
+
+	It("Counts pod-minutes and time with backlog above capacity", func() {
+		result, err := Simulate(config, seriesOf(30, 30, 30, 30), 1)
+		Expect(err).ToNot(HaveOccurred())
+
+		// replicas 2, 2, 3, 3 during subsequent minutes, capacity of 2 replicas is below backlog of 30
+		Expect(result.PodMinutes).To(Equal(10.0))
+		Expect(result.BacklogAboveCapacity).To(Equal(2 * time.Minute))
+	})
+
+	It("Applies hourly limits of simulated time", func() {
+		config.HourlyConfig = []*HourlyConfig{
+			{Name: "business-hours", StartHour: 8, EndHour: 16, MinMaxConfig: MinMaxConfig{MinimumNumberOfPods: 0, MaximumNumberOfPods: 1}},
+		}
+
+		result, err := Simulate(config, seriesOf(30, 30, 30, 30), 0)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(result.Actions).To(Equal(1))
+		Expect(result.Timeline[1].Replicas).To(Equal(1))
+	})
+
+	It("Uses the most recent sample between sparse readouts", func() {
+		series := []Sample{
+			{Timestamp: start, Value: 30},
+			{Timestamp: start.Add(5 * time.Minute), Value: 0},
+		}
+
+		result, err := Simulate(config, series, 0)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(result.Timeline[len(result.Timeline)-1].Replicas).To(Equal(3))
+	})
+
+	It("Restores real clock after simulation", func() {
+		_, err := Simulate(config, seriesOf(30), 0)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(now()).To(BeTemporally("~", time.Now(), time.Second))
+	})
+
+	DescribeTable("Returns error when config can't be simulated",
+		func(modify func(*Config), series []Sample, expectedErr error) {
+			modify(&config)
+
+			_, err := Simulate(config, series, 0)
+			Expect(err).To(Equal(expectedErr))
+		},
+		Entry("When series is empty", func(*Config) {}, nil, ErrEmptySeries),
+		Entry("When threshold is 0", func(c *Config) { c.Threshold = 0 }, []Sample{{}}, ErrSimulationThreshold),
+		Entry("When check interval is 0", func(c *Config) { c.CheckInterval = 0 }, []Sample{{}}, ErrSimulationCheckInterval),
+	)
+})
@@ -0,0 +1,73 @@
+package scaler
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/AirHelp/autoscaler/helper"
+)
+
+const (
+	stateAnnotation = "autoscaler.airhelp.com/state"
+	stateVersion    = 1
+)
+
+// state is persisted in deployment annotation so autoscaler restart doesn't reset cooldown and consecutive zeros history.
+// Bump stateVersion when changing format, states in unknown versions are ignored.
+type state struct {
+	Version      int       `json:"version"`
+	LastResults  []int     `json:"last_results"`
+	LastActionAt time.Time `json:"last_action_at"`
+}
+
+func (s *Scaler) persistState(ctx context.Context) {
+	raw, err := json.Marshal(state{
+		Version:      stateVersion,
+		LastResults:  s.lastTenResults,
+		LastActionAt: s.lastActionAt,
+	})
+	if err != nil {
+		zap.S().With("deployment", s.deploymentName).With("error", err).Warn("failed to serialize scaler state")
+		return
+	}
+
+	if string(raw) == s.persistedState {
+		return
+	}
+
+	if err := s.k8sService.AnnotateDeployment(ctx, s.deploymentName, map[string]string{stateAnnotation: string(raw)}); err != nil {
+		zap.S().With("deployment", s.deploymentName).With("error", err).Warn("failed to persist scaler state")
+		return
+	}
+
+	s.persistedState = string(raw)
+}
+
+func (s *Scaler) restoreState() {
+	scalerLogger := zap.S().With("deployment", s.deploymentName)
+
+	raw, ok := s.deployment.GetAnnotations()[stateAnnotation]
+	if !ok {
+		scalerLogger.Debug("no persisted scaler state found")
+		return
+	}
+
+	var st state
+	if err := json.Unmarshal([]byte(raw), &st); err != nil {
+		scalerLogger.With("error", err).Warn("failed to parse persisted scaler state, ignoring")
+		return
+	}
+
+	if st.Version != stateVersion {
+		scalerLogger.Warnf("persisted scaler state in unsupported version %d, ignoring", st.Version)
+		return
+	}
+
+	s.lastTenResults = helper.Last(st.LastResults, resultsToStore)
+	s.lastActionAt = st.LastActionAt
+	s.persistedState = raw
+	scalerLogger.Debugf("restored scaler state, last action at %v, last probe runs %+v", s.lastActionAt, s.lastTenResults)
+}
@@ -0,0 +1,131 @@
+package scaler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	scalerMock "github.com/AirHelp/autoscaler/scaler/mock"
+)
+
+var _ = Describe("State", func() {
+	var (
+		mockCtrl       *gomock.Controller
+		k8sServiceMock *scalerMock.MockK8SClient
+
+		ctx        context.Context
+		deployment appsv1.Deployment
+		sc         Scaler
+
+		deploymentName = "test-deployment"
+		lastActionAt   = time.Date(2020, 12, 14, 15, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mockCtrl = gomock.NewController(GinkgoT())
+		k8sServiceMock = scalerMock.NewMockK8SClient(mockCtrl)
+
+		deployment = appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: deploymentName,
+			},
+		}
+
+		sc = Scaler{
+			deploymentName: deploymentName,
+			deployment:     &deployment,
+			k8sService:     k8sServiceMock,
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Describe("persistState()", func() {
+		It("Stores versioned state in deployment annotation", func() {
+			sc.lastTenResults = []int{5, 0, 0}
+			sc.lastActionAt = lastActionAt
+
+			k8sServiceMock.EXPECT().AnnotateDeployment(ctx, deploymentName, map[string]string{
+				stateAnnotation: `{"version":1,"last_results":[5,0,0],"last_action_at":"2020-12-14T15:00:00Z"}`,
+			})
+
+			sc.persistState(ctx)
+		})
+
+		It("Does not patch deployment when state didn't change", func() {
+			sc.lastTenResults = []int{5, 0, 0}
+			sc.lastActionAt = lastActionAt
+
+			k8sServiceMock.EXPECT().AnnotateDeployment(ctx, deploymentName, gomock.Any()).Times(1)
+
+			sc.persistState(ctx)
+			sc.persistState(ctx)
+		})
+
+		It("Does not patch deployment with state it was restored from", func() {
+			deployment.Annotations = map[string]string{
+				stateAnnotation: `{"version":1,"last_results":[5,0,0],"last_action_at":"2020-12-14T15:00:00Z"}`,
+			}
+			sc.restoreState()
+
+			sc.persistState(ctx)
+		})
+
+		It("Does not fail when annotating deployment fails", func() {
+			k8sServiceMock.EXPECT().AnnotateDeployment(ctx, deploymentName, gomock.Any()).Return(errors.New("forbidden"))
+
+			sc.persistState(ctx)
+		})
+
+		It("Retries when annotating deployment failed", func() {
+			gomock.InOrder(
+				k8sServiceMock.EXPECT().AnnotateDeployment(ctx, deploymentName, gomock.Any()).Return(errors.New("forbidden")),
+				k8sServiceMock.EXPECT().AnnotateDeployment(ctx, deploymentName, gomock.Any()),
+			)
+
+			sc.persistState(ctx)
+			sc.persistState(ctx)
+		})
+	})
+
+	Describe("restoreState()", func() {
+		DescribeTable("Restores state from deployment annotation",
+			func(annotation string, expectedResults []int, expectedLastActionAt time.Time) {
+				deployment.Annotations = map[string]string{stateAnnotation: annotation}
+
+				sc.restoreState()
+
+				Expect(sc.lastTenResults).To(Equal(expectedResults))
+				Expect(sc.lastActionAt).To(Equal(expectedLastActionAt))
+			},
+			Entry("When state is valid",
+				`{"version":1,"last_results":[5,0,0],"last_action_at":"2020-12-14T15:00:00Z"}`,
+				[]int{5, 0, 0}, lastActionAt),
+			Entry("When state holds too many results",
+				`{"version":1,"last_results":[1,2,3,4,5,6,7,8,9,10,11,12],"last_action_at":"2020-12-14T15:00:00Z"}`,
+				[]int{3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, lastActionAt),
+			Entry("When state is in unsupported version",
+				`{"version":99,"last_results":[5,0,0],"last_action_at":"2020-12-14T15:00:00Z"}`,
+				[]int(nil), time.Time{}),
+			Entry("When state is malformed",
+				`{"version":`,
+				[]int(nil), time.Time{}),
+		)
+
+		It("Does nothing when there is no persisted state", func() {
+			sc.restoreState()
+
+			Expect(sc.lastTenResults).To(BeNil())
+			Expect(sc.lastActionAt).To(BeZero())
+		})
+	})
+})
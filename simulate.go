@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AirHelp/autoscaler/scaler"
+	flag "github.com/spf13/pflag"
+)
+
+const simulateCommand = "simulate"
+
+func isSimulateCommand() bool {
+	return len(os.Args) > 1 && os.Args[1] == simulateCommand
+}
+
+// runSimulate replays probe series through decision logic of given scaler config and prints what autoscaler would do
+func runSimulate(args []string) error {
+	flags := flag.NewFlagSet(simulateCommand, flag.ContinueOnError)
+	configPath := flags.String("config", "", "Path to scaler config, same as single deployment entry of autoscaler configmap")
+	seriesPath := flags.String("series", "", "Path to probe series, CSV or JSONL file of (timestamp, value)")
+	seriesFormat := flags.String("format", "", "Format of probe series: csv or jsonl, detected from file extension by default")
+	initialReplicas := flags.Int("initial_replicas", 0, "Replicas at the beginning of simulation, minimum number of pods by default")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *configPath == "" || *seriesPath == "" {
+		return fmt.Errorf("usage: autoscaler %v --config <scaler config> --series <probe series>", simulateCommand)
+	}
+
+	rawConfig, err := os.ReadFile(*configPath)
+	if err != nil {
+		return err
+	}
+
+	scalerConfig, err := scaler.ParseRawScalerConfig(string(rawConfig))
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	if *seriesFormat == "" {
+		*seriesFormat = scaler.SeriesFormatCSV
+		if ext := strings.ToLower(filepath.Ext(*seriesPath)); ext == ".jsonl" || ext == ".json" {
+			*seriesFormat = scaler.SeriesFormatJSONL
+		}
+	}
+
+	seriesFile, err := os.Open(*seriesPath)
+	if err != nil {
+		return err
+	}
+	defer seriesFile.Close()
+
+	series, err := scaler.ReadSeries(seriesFile, *seriesFormat)
+	if err != nil {
+		return fmt.Errorf("failed to read series: %w", err)
+	}
+
+	if !flags.Changed("initial_replicas") {
+		*initialReplicas = scalerConfig.MinimumNumberOfPods
+	}
+
+	result, err := scaler.Simulate(scalerConfig, series, *initialReplicas)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("replica timeline:")
+	for _, step := range result.Timeline {
+		decision := step.Decision
+		if decision == "" {
+			decision = "initial replicas"
+		}
+
+		fmt.Printf("  %v  replicas: %3d  probe: %6d  %v\n", step.Time.Format(time.RFC3339), step.Replicas, step.ProbeValue, decision)
+	}
+
+	fmt.Printf("scale actions: %d\n", result.Actions)
+	fmt.Printf("pod-minutes: %.1f\n", result.PodMinutes)
+	fmt.Printf("backlog above capacity: %v\n", result.BacklogAboveCapacity)
+
+	return nil
+}